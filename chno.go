@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ChannelNumbers assigns tvg-chno values that stay the same across runs. Every group numbers its channels from
+// its own base, and the numbers given once are kept in a mapping file.
+type ChannelNumbers struct {
+	// Base of the groups without one in GroupBases
+	Base int
+	// Base by lower-cased group name
+	GroupBases map[string]int
+	// Numbers already given, by group and channel key
+	Assigned map[string]map[string]int
+}
+
+func newChannelNumbers(base int, groupBases map[string]int) *ChannelNumbers {
+	c := &ChannelNumbers{Base: base, GroupBases: make(map[string]int), Assigned: make(map[string]map[string]int)}
+	for group, groupBase := range groupBases {
+		c.GroupBases[strings.ToLower(group)] = groupBase
+	}
+	return c
+}
+
+// channelKey identifies a channel across runs: its tvg-id, or its stream URI since titles of event channels
+// change every day. The query is kept ("play.php?id=1"), only credentials are left out.
+func channelKey(e *PlaylistEntry) string {
+	if id := strings.TrimSpace(e.Info.TvgID()); id != "" {
+		return "id:" + strings.ToLower(id)
+	}
+	uri := strings.TrimSpace(e.URI)
+	if u, err := url.Parse(uri); err == nil && u.User != nil {
+		u.User = nil
+		uri = u.String()
+	}
+	return "uri:" + uri
+}
+
+// load reads the mapping file written by save, a missing file being an empty mapping.
+func (c *ChannelNumbers) load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var assigned map[string]map[string]int
+	if err := json.Unmarshal(data, &assigned); err != nil {
+		return err
+	}
+	for group, channels := range assigned {
+		for key, chno := range channels {
+			c.set(group, key, chno)
+		}
+	}
+	return nil
+}
+
+func (c *ChannelNumbers) save(path string) error {
+	data, err := json.MarshalIndent(c.Assigned, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func (c *ChannelNumbers) set(group, key string, chno int) {
+	group = strings.ToLower(group)
+	if c.Assigned[group] == nil {
+		c.Assigned[group] = make(map[string]int)
+	}
+	c.Assigned[group][key] = chno
+}
+
+func (c *ChannelNumbers) base(group string) int {
+	if base, ok := c.GroupBases[strings.ToLower(group)]; ok {
+		return base
+	}
+	return c.Base
+}
+
+// assignChannelNumbers sets tvg-chno on every entry: the number it had in a previous run, or the first free
+// number from the base of its group, in playlist order. Numbers are unique across all groups.
+func (p *Playlist) assignChannelNumbers(c *ChannelNumbers) {
+	used := make(map[int]bool)
+	for _, channels := range c.Assigned {
+		for _, chno := range channels {
+			used[chno] = true
+		}
+	}
+	var pending []*PlaylistEntry
+	for _, e := range p.Entries {
+		if chno, ok := c.Assigned[strings.ToLower(e.Info.GroupTitle())][channelKey(e)]; ok {
+			e.Info.SetTvgChno(chno)
+			continue
+		}
+		pending = append(pending, e)
+	}
+	next := make(map[string]int)
+	for _, e := range pending {
+		group := strings.ToLower(e.Info.GroupTitle())
+		key := channelKey(e)
+		// Several entries of the same channel (duplicates kept) share the number given to the first
+		if chno, ok := c.Assigned[group][key]; ok {
+			e.Info.SetTvgChno(chno)
+			continue
+		}
+		chno, ok := next[group]
+		if !ok {
+			chno = c.base(group)
+		}
+		for used[chno] {
+			chno++
+		}
+		used[chno] = true
+		next[group] = chno + 1
+		c.set(group, key, chno)
+		e.Info.SetTvgChno(chno)
+	}
+}
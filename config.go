@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds settings that are too long or too personal for flags. Flags given on the command line win.
+type Config struct {
+	// Favorite teams by acronym or name, see --favorites
+	Favorites []string `json:"favorites" yaml:"favorites"`
+	// Prefix added to the title of favorite games
+	FavoriteMarker string `json:"favorite_marker" yaml:"favorite_marker"`
+	// Rules removing placeholder entries, replacing the defaults when given (see --keep-placeholders)
+	JunkRules []JunkRule `json:"junk_rules" yaml:"junk_rules"`
+	// Title prefixes and group names mapped to ISO country codes, added to the defaults
+	CountryPrefixes map[string]string `json:"country_prefixes" yaml:"country_prefixes"`
+	// What --parental removes; each list given replaces its default
+	Parental ParentalConfig `json:"parental" yaml:"parental"`
+	// Group renames and merges, in output order
+	GroupMap []GroupMapping `json:"group_map" yaml:"group_map"`
+	// Drop the groups matched by no mapping of GroupMap
+	HideUnmappedGroups bool `json:"hide_unmapped_groups" yaml:"hide_unmapped_groups"`
+	// Channel numbering, see --chno
+	ChannelNumbers ChannelNumberConfig `json:"channel_numbers" yaml:"channel_numbers"`
+	// Columns of --format csv and tsv
+	CSVColumns []string `json:"csv_columns" yaml:"csv_columns"`
+}
+
+type ChannelNumberConfig struct {
+	// First number of the groups without their own base
+	Base int `json:"base" yaml:"base"`
+	// First number by group name
+	Groups map[string]int `json:"groups" yaml:"groups"`
+	// Mapping file keeping the numbers across runs
+	MapFile string `json:"map_file" yaml:"map_file"`
+}
+
+func defaultConfig() *Config {
+	return &Config{
+		FavoriteMarker:  "★ ",
+		CountryPrefixes: defaultCountryPrefixes(),
+		Parental:        defaultParentalConfig(),
+		ChannelNumbers:  ChannelNumberConfig{Base: 1},
+	}
+}
+
+// fillDefaults sets the lists the config file left out. They are not decoded over: a rule of the file would keep
+// the fields of the default rule at its position, and csv_columns would overwrite the package defaults.
+func (c *Config) fillDefaults() {
+	if c.JunkRules == nil {
+		c.JunkRules = defaultJunkRules()
+	}
+	if c.CSVColumns == nil {
+		c.CSVColumns = slices.Clone(defaultCSVColumns)
+	}
+}
+
+// loadConfig reads a JSON or YAML config file over the defaults.
+func loadConfig(path string) (*Config, error) {
+	config := defaultConfig()
+	if path == "" {
+		config.fillDefaults()
+		return config, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, config)
+	default:
+		err = json.Unmarshal(data, config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	config.fillDefaults()
+	return config, nil
+}
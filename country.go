@@ -0,0 +1,130 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Title prefixes and group names mapped to ISO 3166 country codes. Keys are upper-case.
+func defaultCountryPrefixes() map[string]string {
+	return map[string]string{
+		"US": "US", "USA": "US", "UNITED STATES": "US",
+		"UK": "GB", "GB": "GB", "UNITED KINGDOM": "GB",
+		"CA": "CA", "CANADA": "CA",
+		"BR": "BR", "BRAZIL": "BR", "BRASIL": "BR",
+		"DE": "DE", "GERMANY": "DE",
+		"NL": "NL", "NETHERLANDS": "NL", "NETHERLAND": "NL",
+		"MT": "MT", "MALTA": "MT",
+		"FR": "FR", "FRANCE": "FR",
+		"ES": "ES", "SPAIN": "ES",
+		"PT": "PT", "PORTUGAL": "PT",
+		"ITALY": "IT",
+		"MX":    "MX", "MEXICO": "MX",
+	}
+}
+
+type CountryDetector struct {
+	prefixes map[string]string
+	// Matches a known prefix at the start of a title or group, with an optional quality ("UKFHD", "UK FHD") and
+	// the separators after it
+	rePrefix *regexp.Regexp
+}
+
+func newCountryDetector(prefixes map[string]string) *CountryDetector {
+	normalized := make(map[string]string, len(prefixes))
+	keys := make([]string, 0, len(prefixes))
+	for prefix, code := range prefixes {
+		prefix = strings.ToUpper(strings.TrimSpace(prefix))
+		if prefix == "" || code == "" {
+			continue
+		}
+		normalized[prefix] = strings.ToUpper(code)
+		keys = append(keys, prefix)
+	}
+	// Longest first, so "USA" wins over "US"
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	// Codes match in upper case only, "De La Hoya" or "Us Weekly" are no prefixes. Names match in any case.
+	var alternatives []string
+	for _, key := range keys {
+		if len(key) <= 3 {
+			alternatives = append(alternatives, regexp.QuoteMeta(key))
+		} else {
+			alternatives = append(alternatives, `(?i:`+regexp.QuoteMeta(key)+`)`)
+		}
+	}
+	return &CountryDetector{
+		prefixes: normalized,
+		rePrefix: regexp.MustCompile(`^\s*(` + strings.Join(alternatives, "|") + `)(?i:\s*(?:4K|UHD|FHD|HD|SD))?(?:\s*[:|]\s*|\s+|$)`),
+	}
+}
+
+// titlePrefix returns the country code and the whole prefix (separators included) a text starts with.
+func (c *CountryDetector) titlePrefix(text string) (code, prefix string) {
+	m := c.rePrefix.FindStringSubmatch(text)
+	if m == nil {
+		return "", ""
+	}
+	return c.prefixes[strings.ToUpper(m[1])], m[0]
+}
+
+// Detect returns the country of an entry: from tvg-country when present, then the title prefix, then the group.
+func (c *CountryDetector) Detect(e *PlaylistEntry) string {
+	if country := strings.TrimSpace(e.Info.GetAttr("tvg-country")); country != "" {
+		return strings.ToUpper(country)
+	}
+	if code, _ := c.titlePrefix(e.Info.TitleCopy); code != "" {
+		return code
+	}
+	groupTitle := strings.TrimSpace(e.Info.GroupTitle())
+	if code, ok := c.prefixes[strings.ToUpper(groupTitle)]; ok {
+		return code
+	}
+	code, _ := c.titlePrefix(groupTitle)
+	return code
+}
+
+// tagCountries sets a derived tvg-country on every entry with a known country, and removes the country prefix
+// from the displayed title when asked. The country of a stripped prefix is always written, it is no longer in the
+// title.
+func (p *Playlist) tagCountries(detector *CountryDetector, stripPrefix bool) {
+	for _, e := range p.Entries {
+		code := detector.Detect(e)
+		if code != "" {
+			e.Info.SetDerivedAttr("tvg-country", code)
+		}
+		if !stripPrefix {
+			continue
+		}
+		if _, prefix := detector.titlePrefix(e.Info.Title); prefix != "" && len(prefix) < len(e.Info.Title) {
+			e.Info.Title = e.Info.Title[len(prefix):]
+			if code != "" {
+				e.Info.SetAttr("tvg-country", code)
+			}
+		}
+	}
+}
+
+// filterCountries keeps the entries of the given countries (ISO codes or known prefixes like "USA").
+func (p *Playlist) filterCountries(detector *CountryDetector, countries []string) {
+	wanted := make(map[string]bool)
+	for _, country := range countries {
+		country = strings.ToUpper(strings.TrimSpace(country))
+		if code, ok := detector.prefixes[country]; ok {
+			country = code
+		}
+		wanted[country] = true
+	}
+	out := p.Entries[:0]
+	for _, e := range p.Entries {
+		if wanted[strings.ToUpper(e.Info.GetAttr("tvg-country"))] {
+			out = append(out, e)
+		}
+	}
+	p.Entries = out
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCountryDetector(t *testing.T) {
+	detector := newCountryDetector(defaultCountryPrefixes())
+	tests := []struct {
+		title    string
+		group    string
+		country  string
+		stripped string
+	}{
+		{"USA  ESPN", "United States", "US", "ESPN"},
+		{"US: TNT", "USA Premium", "US", "TNT"},
+		{"UK FHD  Sky Sports F1 FHD", "SPORTS", "GB", "Sky Sports F1 FHD"},
+		{"UKFHD | Sky Sports Main Event 50 FPS", "SPORTS", "GB", "Sky Sports Main Event 50 FPS"},
+		{"BR: SPORTV", "Brazil", "BR", "SPORTV"},
+		{"Germany  Sky Sport Bundesliga 1 HD DE", "Germany", "DE", "Sky Sport Bundesliga 1 HD DE"},
+		{"Netherland  ZIGGO SPORT Voetbal HD", "Netherlands", "NL", "ZIGGO SPORT Voetbal HD"},
+		{"Globo SP HD", "Brazil", "BR", "Globo SP HD"},
+		{"The Walking Dead", "US Movies", "US", "The Walking Dead"},
+		{"USA | NBA 05ⓧ: Sacramento Kings vs Miami Heat", "NBA", "US", "NBA 05ⓧ: Sacramento Kings vs Miami Heat"},
+		{"Discovery Plus | Discovery Turbo", "Discovery Plus", "", "Discovery Plus | Discovery Turbo"},
+		{"USAGI Anime", "SPORTS", "", "USAGI Anime"},
+		// Codes are upper case
+		{"De La Hoya Boxing", "SPORTS", "", "De La Hoya Boxing"},
+		{"Es Noticia", "News", "", "Es Noticia"},
+		{"Us Weekly", "Entertainment", "", "Us Weekly"},
+		{"Ca Va", "Es Noticia", "", "Ca Va"},
+		{"Pt Lights", "SPORTS", "", "Pt Lights"},
+		{"US History Channel (H)", "Documentary", "US", "History Channel (H)"},
+	}
+	for _, tt := range tests {
+		p := Playlist{Entries: []*PlaylistEntry{newTestEntry(tt.title, map[string]string{"group-title": tt.group}, nil)}}
+		p.tagCountries(detector, true)
+		e := p.Entries[0]
+		if got := e.Info.GetAttr("tvg-country"); got != tt.country {
+			t.Errorf("%q [%s]: tvg-country = %q, want %q", tt.title, tt.group, got, tt.country)
+		}
+		if e.Info.Title != tt.stripped {
+			t.Errorf("%q: stripped title = %q, want %q", tt.title, e.Info.Title, tt.stripped)
+		}
+	}
+}
+
+func TestCountryDetector_ConfiguredPrefixAndFilter(t *testing.T) {
+	prefixes := defaultCountryPrefixes()
+	prefixes["Portugal TV"] = "pt"
+	detector := newCountryDetector(prefixes)
+	p := Playlist{Entries: []*PlaylistEntry{
+		newTestEntry("Portugal TV | RTP 1", nil, nil),
+		newTestEntry("ESPN", map[string]string{"tvg-country": "us"}, nil),
+		newTestEntry("Canal+", map[string]string{"group-title": "France"}, nil),
+		newTestEntry("Unknown", nil, nil),
+	}}
+	p.tagCountries(detector, false)
+	p.filterCountries(detector, []string{"PT", "usa"})
+	if len(p.Entries) != 2 || p.Entries[0].Info.GetAttr("tvg-country") != "PT" || p.Entries[1].Info.GetAttr("tvg-country") != "US" {
+		for _, e := range p.Entries {
+			t.Logf("kept %q %q", e.Info.Title, e.Info.GetAttr("tvg-country"))
+		}
+		t.Errorf("filterCountries kept %d entries, want PT and US", len(p.Entries))
+	}
+}
+
+func TestTagCountries_Written(t *testing.T) {
+	detector := newCountryDetector(defaultCountryPrefixes())
+	p := Playlist{Entries: []*PlaylistEntry{
+		newTestEntry("ESPN", map[string]string{"group-title": "United States"}, nil),
+		newTestEntry("USA | ESPN", nil, nil),
+	}}
+	p.tagCountries(detector, true)
+	if line := writeNewEntry(p.Entries[0]); strings.Contains(line, "tvg-country") {
+		t.Errorf("detected country written without --annotate: %q", line)
+	}
+	// The prefix is gone from the title, the country is kept
+	if line := writeNewEntry(p.Entries[1]); !strings.Contains(line, `tvg-country="US"`) {
+		t.Errorf("country of the stripped prefix not written: %q", line)
+	}
+}
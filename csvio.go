@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Output formats for spreadsheets, see --format
+const (
+	FormatCSV = "csv"
+	FormatTSV = "tsv"
+)
+
+// Columns written by default. "attributes" holds every attribute without a column of its own, so nothing is
+// lost on the way back.
+var defaultCSVColumns = []string{"title", "group-title", "tvg-id", "tvg-name", "tvg-logo", "tvg-chno", "uri", "start", "favorite", "attributes"}
+
+// Column names of parsed fields. Any other column is an attribute; the --where field names are accepted for
+// the common ones.
+const (
+	csvColumnTitle         = "title"
+	csvColumnOriginalTitle = "original_title"
+	csvColumnDuration      = "duration"
+	csvColumnURI           = "uri"
+	csvColumnStart         = "start"
+	csvColumnFavorite      = "favorite"
+	csvColumnMatch         = "match"
+	csvColumnAttributes    = "attributes"
+)
+
+var csvColumnAliases = map[string]string{
+	"group": "group-title", "id": "tvg-id", "name": "tvg-name", "logo": "tvg-logo", "chno": "tvg-chno",
+	"home": "nba-home", "away": "nba-away",
+}
+
+// normalizeCSVColumns lower-cases the column names and resolves the aliases.
+func normalizeCSVColumns(columns []string) []string {
+	out := make([]string, 0, len(columns))
+	for _, column := range columns {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if column == "" {
+			continue
+		}
+		if alias, ok := csvColumnAliases[column]; ok {
+			column = alias
+		}
+		out = append(out, column)
+	}
+	return out
+}
+
+func csvComma(format string) rune {
+	if format == FormatTSV {
+		return '\t'
+	}
+	return ','
+}
+
+func csvValue(e *PlaylistEntry, column string, columns map[string]bool) string {
+	switch column {
+	case csvColumnTitle:
+		return e.Info.Title
+	case csvColumnOriginalTitle:
+		return e.Info.TitleCopy
+	case csvColumnDuration:
+		return strconv.Itoa(e.Info.Duration)
+	case csvColumnURI:
+		return e.URI
+	case csvColumnStart:
+		if e.Info.StartTimeLocal == nil {
+			return ""
+		}
+		return e.Info.StartTimeLocal.Format(time.RFC3339)
+	case csvColumnFavorite:
+		if e.Info.Favorite {
+			return "yes"
+		}
+		return ""
+	case csvColumnMatch:
+		return e.Info.NBAMatchId()
+	case csvColumnAttributes:
+		// The remaining attributes, the way they are written in #EXTINF
+		var b strings.Builder
+		for _, key := range e.Info.OutputAttrKeys() {
+			if columns[key] {
+				continue
+			}
+			if b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			fmt.Fprintf(&b, "%s=\"%s\"", key, e.Info.GetAttr(key))
+		}
+		return b.String()
+	}
+	return e.Info.GetAttr(column)
+}
+
+// writePlaylistCSV writes one row per entry with the given columns, comma- or tab-separated.
+func writePlaylistCSV(outPath string, entries []*PlaylistEntry, columns []string, format string) error {
+	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	w.Comma = csvComma(format)
+	columns = normalizeCSVColumns(columns)
+	hasColumn := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		hasColumn[column] = true
+	}
+	if err := w.Write(columns); err != nil {
+		return err
+	}
+	row := make([]string, len(columns))
+	for _, e := range entries {
+		for i, column := range columns {
+			row[i] = csvValue(e, column, hasColumn)
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// parsePlaylistCSV rebuilds a playlist from a CSV or TSV file with a header row. Rows without a URI are
+// skipped; columns that are not parsed fields become attributes, added or edited ones included.
+func parsePlaylistCSV(path string) (Playlist, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Playlist{}, err
+	}
+	defer f.Close()
+	format := FormatCSV
+	if strings.EqualFold(filepath.Ext(path), ".tsv") {
+		format = FormatTSV
+	}
+	r := csv.NewReader(bufio.NewReader(f))
+	r.Comma = csvComma(format)
+	r.FieldsPerRecord = -1
+	if format == FormatTSV {
+		r.LazyQuotes = true
+	}
+	records, err := r.ReadAll()
+	if err != nil {
+		return Playlist{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return Playlist{}, fmt.Errorf("%s: missing header row", path)
+	}
+	columns := normalizeCSVColumns(records[0])
+	if len(columns) != len(records[0]) {
+		return Playlist{}, fmt.Errorf("%s: empty column name in header row", path)
+	}
+	playlist := Playlist{HeaderPresent: true}
+	for n, record := range records[1:] {
+		e, err := csvEntry(columns, record)
+		if err != nil {
+			return Playlist{}, fmt.Errorf("%s: row %d: %w", path, n+2, err)
+		}
+		if e != nil {
+			playlist.Entries = append(playlist.Entries, e)
+		}
+	}
+	return playlist, nil
+}
+
+func csvEntry(columns, record []string) (*PlaylistEntry, error) {
+	e := &PlaylistEntry{}
+	e.Info.Duration = -1
+	var attributes string
+	for i, column := range columns {
+		if i >= len(record) {
+			break
+		}
+		value := strings.TrimSpace(record[i])
+		switch column {
+		case csvColumnTitle:
+			e.Info.Title = value
+		case csvColumnOriginalTitle:
+			e.Info.TitleCopy = value
+		case csvColumnDuration:
+			if value == "" {
+				continue
+			}
+			duration, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("duration %q: %w", value, err)
+			}
+			e.Info.Duration = duration
+		case csvColumnURI:
+			e.URI = value
+		case csvColumnStart:
+			if value == "" {
+				continue
+			}
+			start, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, fmt.Errorf("start %q: %w", value, err)
+			}
+			start = start.In(time.Local)
+			e.Info.StartTimeLocal = &start
+		case csvColumnFavorite:
+			switch strings.ToLower(value) {
+			case "", "no", "false", "0", "n":
+			default:
+				e.Info.Favorite = true
+			}
+		case csvColumnMatch:
+			if value != "" {
+				e.Info.SetAttr("nba-match-id", value)
+			}
+		case csvColumnAttributes:
+			attributes = value
+		default:
+			if value != "" {
+				e.Info.SetAttr(column, value)
+			}
+		}
+	}
+	if e.URI == "" {
+		return nil, nil
+	}
+	for _, m := range attrKVQuoted.FindAllStringSubmatch(attributes, -1) {
+		if key := strings.ToLower(m[1]); !e.Info.HasAttr(key) {
+			e.Info.SetAttr(key, m[2])
+		}
+	}
+	if e.Info.TitleCopy == "" {
+		e.Info.TitleCopy = e.Info.Title
+	}
+	return e, nil
+}
+
+// isCSVPlaylist tells CSV and TSV inputs apart by extension.
+func isCSVPlaylist(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".tsv":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPlaylistCSV_RoundTrip(t *testing.T) {
+	start := time.Date(2025, 12, 6, 20, 0, 0, 0, time.Local)
+	entries := []*PlaylistEntry{
+		newTestEntry("US: HBO", map[string]string{"tvg-id": "hbo.us", "group-title": "US Movies", "catchup": "shift"}, nil),
+		newTestEntry("NBA 09: Heat, Kings", map[string]string{"group-title": "NBA", "tvg-chno": "101"}, &start),
+	}
+	entries[1].Info.Favorite = true
+	for _, format := range []string{FormatCSV, FormatTSV} {
+		path := filepath.Join(t.TempDir(), "out."+format)
+		if err := writePlaylistCSV(path, entries, defaultCSVColumns, format); err != nil {
+			t.Fatal(err)
+		}
+		got, err := parsePlaylistCSV(path)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if len(got.Entries) != len(entries) {
+			t.Fatalf("%s: got %d entries, want %d", format, len(got.Entries), len(entries))
+		}
+		for i, e := range got.Entries {
+			want := entries[i]
+			if e.URI != want.URI || e.Info.Title != want.Info.Title || e.Info.Favorite != want.Info.Favorite {
+				t.Errorf("%s #%d: got %q %q %v", format, i, e.Info.Title, e.URI, e.Info.Favorite)
+			}
+			for _, key := range want.Info.AttrKeys() {
+				if e.Info.GetAttr(key) != want.Info.GetAttr(key) {
+					t.Errorf("%s #%d: %s = %q, want %q", format, i, key, e.Info.GetAttr(key), want.Info.GetAttr(key))
+				}
+			}
+		}
+		if s := got.Entries[1].Info.StartTimeLocal; s == nil || !s.Equal(start) {
+			t.Errorf("%s: start = %v, want %v", format, s, start)
+		}
+	}
+}
+
+func TestParsePlaylistCSV_Edited(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "edited.csv")
+	content := "Title,Group,uri,favorite,my-tag,attributes\n" +
+		"ESPN,Sports,http://example.com/1,x,kept,\"tvg-id=\"\"espn.us\"\" group-title=\"\"Old\"\"\"\n" +
+		"Deleted URI,Sports,,,,\n" +
+		"Fox,Sports,http://example.com/2,no,,\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := parsePlaylistCSV(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Entries) != 2 {
+		t.Fatalf("got %d entries, want 2 (row without URI skipped)", len(p.Entries))
+	}
+	espn := p.Entries[0]
+	if espn.Info.GroupTitle() != "Sports" || espn.Info.TvgID() != "espn.us" || espn.Info.GetAttr("my-tag") != "kept" || !espn.Info.Favorite {
+		t.Errorf("ESPN = %+v", espn.Info)
+	}
+	if p.Entries[1].Info.Favorite {
+		t.Error("Fox should not be a favorite")
+	}
+}
+
+func TestLoadConfig_CSVColumns(t *testing.T) {
+	defaults := slices.Clone(defaultCSVColumns)
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"csv_columns": ["uri", "title"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(config.CSVColumns, []string{"uri", "title"}) {
+		t.Errorf("columns = %v", config.CSVColumns)
+	}
+	if !slices.Equal(defaultCSVColumns, defaults) {
+		t.Errorf("default columns changed to %v", defaultCSVColumns)
+	}
+}
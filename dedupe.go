@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Dedupe strategies, see --dedupe
+const (
+	DedupeURI           = "uri"            // identical stream URL
+	DedupeURINormalized = "uri-normalized" // same URL ignoring credentials, query, fragment and case of the host
+	DedupeTitleGroup    = "title-group"    // same normalized title in the same group
+	DedupeTvgID         = "tvg-id"         // same non-empty tvg-id
+)
+
+// Which duplicate survives, see --dedupe-keep
+const (
+	DedupeKeepFirst   = "first"
+	DedupeKeepQuality = "quality"
+	DedupeKeepGroup   = "group:" // followed by a group pattern
+)
+
+// DuplicateSet is a group of entries collapsed into Kept.
+type DuplicateSet struct {
+	Strategy string
+	Key      string
+	Kept     *PlaylistEntry
+	Dropped  []*PlaylistEntry
+}
+
+type Deduper struct {
+	Strategies []string
+	keep       string
+	keepGroup  GroupPattern
+}
+
+func newDeduper(strategies []string, keep string) (*Deduper, error) {
+	d := &Deduper{keep: keep}
+	for _, strategy := range strategies {
+		strategy = strings.TrimSpace(strategy)
+		switch strategy {
+		case DedupeURI, DedupeURINormalized, DedupeTitleGroup, DedupeTvgID:
+			d.Strategies = append(d.Strategies, strategy)
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown dedupe strategy %q, expected %s, %s, %s or %s",
+				strategy, DedupeURI, DedupeURINormalized, DedupeTitleGroup, DedupeTvgID)
+		}
+	}
+	switch {
+	case keep == DedupeKeepFirst || keep == DedupeKeepQuality:
+	case strings.HasPrefix(keep, DedupeKeepGroup):
+		pattern, err := newGroupPattern(strings.TrimPrefix(keep, DedupeKeepGroup))
+		if err != nil {
+			return nil, err
+		}
+		d.keepGroup = pattern
+	default:
+		return nil, fmt.Errorf("unknown dedupe keep %q, expected %s, %s or %s<group>", keep, DedupeKeepFirst, DedupeKeepQuality, DedupeKeepGroup)
+	}
+	return d, nil
+}
+
+func dedupeKey(strategy string, e *PlaylistEntry) string {
+	switch strategy {
+	case DedupeURI:
+		return e.URI
+	case DedupeURINormalized:
+		return normalizeURI(e.URI)
+	case DedupeTitleGroup:
+		title := normalizeChannelTitle(e.Info.TitleCopy)
+		if title == "" {
+			return ""
+		}
+		return strings.ToLower(e.Info.GroupTitle()) + "\x00" + title
+	case DedupeTvgID:
+		return strings.ToLower(strings.TrimSpace(e.Info.TvgID()))
+	}
+	return ""
+}
+
+func normalizeURI(uri string) string {
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil || u.Host == "" {
+		return uri
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	u.Host = host
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return u.String()
+}
+
+// Tokens ignored when comparing channel titles: quality and variant tags, and country prefixes
+var (
+	channelTitleNoise    = map[string]bool{"hd": true, "fhd": true, "uhd": true, "sd": true, "4k": true, "hevc": true, "h265": true, "vip": true, "50": true, "fps": true, "backup": true}
+	channelTitlePrefixes = map[string]bool{"usa": true, "us": true, "uk": true, "ukhd": true, "ukfhd": true}
+)
+
+// normalizeChannelTitle reduces "USA  ESPN" and "ESPN HD" to the same "espn".
+func normalizeChannelTitle(title string) string {
+	tokens := strings.Fields(normalizeTeamText(title))
+	if len(tokens) > 1 && channelTitlePrefixes[tokens[0]] {
+		tokens = tokens[1:]
+	}
+	out := tokens[:0]
+	for _, token := range tokens {
+		if !channelTitleNoise[token] {
+			out = append(out, token)
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+// better reports whether candidate should be kept instead of current.
+func (d *Deduper) better(candidate, current *PlaylistEntry) bool {
+	switch {
+	case d.keep == DedupeKeepQuality:
+		return qualityRank(extractStreamTags(candidate.Info.TitleCopy).Quality) > qualityRank(extractStreamTags(current.Info.TitleCopy).Quality)
+	case strings.HasPrefix(d.keep, DedupeKeepGroup):
+		return d.keepGroup.Match(candidate.Info.GroupTitle()) && !d.keepGroup.Match(current.Info.GroupTitle())
+	}
+	return false
+}
+
+// dedupe collapses duplicates with each strategy in turn, keeping the surviving entry at its own position.
+func (p *Playlist) dedupe(d *Deduper) []DuplicateSet {
+	var sets []DuplicateSet
+	for _, strategy := range d.Strategies {
+		index := make(map[string]int)
+		var strategySets []DuplicateSet
+		for _, e := range p.Entries {
+			key := dedupeKey(strategy, e)
+			if key == "" {
+				continue
+			}
+			i, ok := index[key]
+			if !ok {
+				index[key] = len(strategySets)
+				strategySets = append(strategySets, DuplicateSet{Strategy: strategy, Key: key, Kept: e})
+				continue
+			}
+			set := &strategySets[i]
+			if d.better(e, set.Kept) {
+				set.Dropped = append(set.Dropped, set.Kept)
+				set.Kept = e
+			} else {
+				set.Dropped = append(set.Dropped, e)
+			}
+		}
+
+		dropped := make(map[*PlaylistEntry]bool)
+		for _, set := range strategySets {
+			if len(set.Dropped) == 0 {
+				continue
+			}
+			for _, e := range set.Dropped {
+				dropped[e] = true
+			}
+			sets = append(sets, set)
+		}
+		out := p.Entries[:0]
+		for _, e := range p.Entries {
+			if !dropped[e] {
+				out = append(out, e)
+			}
+		}
+		p.Entries = out
+	}
+	return sets
+}
+
+func writeDedupeReport(path string, sets []DuplicateSet) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	dropped := 0
+	for _, set := range sets {
+		dropped += len(set.Dropped)
+	}
+	fmt.Fprintf(w, "%d duplicate sets, %d entries removed\n", len(sets), dropped)
+	for _, set := range sets {
+		fmt.Fprintf(w, "\n[%s] %s\n", set.Strategy, strings.ReplaceAll(set.Key, "\x00", " | "))
+		fmt.Fprintf(w, "  kept:    %s [%s] %s\n", set.Kept.Info.TitleCopy, set.Kept.Info.GroupTitle(), set.Kept.URI)
+		for _, e := range set.Dropped {
+			fmt.Fprintf(w, "  dropped: %s [%s] %s\n", e.Info.TitleCopy, e.Info.GroupTitle(), e.URI)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
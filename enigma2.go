@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FormatEnigma2 writes one userbouquet per group and the bouquets.tv index, see --format
+const FormatEnigma2 = "enigma2"
+
+// Enigma2 service reference of an IPTV stream (4097: played by the media player, 1: TV)
+const enigma2ServicePrefix = "4097:0:1:0:0:0:0:0:0:0:"
+
+// enigma2BouquetFile names the bouquet of a split key, prefixed so it never overwrites the receiver's own ones.
+func enigma2BouquetFile(key string) string {
+	return "userbouquet.iptv_" + strings.ToLower(sanitizeForFilename(key)) + ".tv"
+}
+
+// enigma2ServiceURL escapes the characters Enigma2 reads as separators (":") or escapes ("%") in a service URL.
+func enigma2ServiceURL(uri string) string {
+	return strings.NewReplacer("%", "%25", ":", "%3a", "\n", "", "\r", "").Replace(uri)
+}
+
+func writeEnigma2Bouquet(outPath, name string, entries []*PlaylistEntry) error {
+	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "#NAME %s\n", name)
+	for _, e := range entries {
+		title := strings.ReplaceAll(e.Info.Title, "\n", " ")
+		fmt.Fprintf(w, "#SERVICE %s%s:%s\n", enigma2ServicePrefix, enigma2ServiceURL(e.URI), title)
+		fmt.Fprintf(w, "#DESCRIPTION %s\n", title)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// writeEnigma2Index writes bouquets.tv listing the bouquet files in order.
+func writeEnigma2Index(outPath string, bouquetFiles []string) error {
+	f, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	fmt.Fprintln(w, "#NAME User - bouquets (TV)")
+	for _, file := range bouquetFiles {
+		fmt.Fprintf(w, "#SERVICE 1:7:1:0:0:0:0:0:0:0:FROM BOUQUET \"%s\" ORDER BY bouquet\n", file)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// writeEnigma2 writes one bouquet per output of generateOutput into dir, in group order (see group_map) then by
+// name, and the bouquets.tv index.
+func writeEnigma2(dir string, outputs map[string]PlaylistOutput) error {
+	keys := make([]string, 0, len(outputs))
+	for key := range outputs {
+		keys = append(keys, key)
+	}
+	bouquetName := func(key string) string {
+		if key == "" {
+			return "Ungrouped"
+		}
+		// Split keys are upper-cased, the group itself reads better
+		if entries := outputs[key].Entries; len(entries) > 0 && strings.EqualFold(entries[0].Info.GroupTitle(), key) {
+			return entries[0].Info.GroupTitle()
+		}
+		return key
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if c := compareGroupOrder(outputs[keys[i]].GroupOrder, bouquetName(keys[i]), bouquetName(keys[j])); c != 0 {
+			return c < 0
+		}
+		return keys[i] < keys[j]
+	})
+	var files []string
+	used := make(map[string]bool)
+	for _, key := range keys {
+		// "US Movies" and "US/Movies" sanitize to the same file: number the later ones
+		file := enigma2BouquetFile(bouquetName(key))
+		for n := 2; used[file]; n++ {
+			file = enigma2BouquetFile(fmt.Sprintf("%s_%d", bouquetName(key), n))
+		}
+		used[file] = true
+		if err := writeEnigma2Bouquet(filepath.Join(dir, file), bouquetName(key), outputs[key].Entries); err != nil {
+			return err
+		}
+		files = append(files, file)
+	}
+	return writeEnigma2Index(filepath.Join(dir, "bouquets.tv"), files)
+}
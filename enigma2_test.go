@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnigma2ServiceURL(t *testing.T) {
+	tests := map[string]string{
+		"http://example.com:8080/live/1.ts":    "http%3a//example.com%3a8080/live/1.ts",
+		"http://example.com/a%20b?x=1&y=2":     "http%3a//example.com/a%2520b?x=1&y=2",
+		"https://example.com/stream/600006497": "https%3a//example.com/stream/600006497",
+	}
+	for uri, want := range tests {
+		if got := enigma2ServiceURL(uri); got != want {
+			t.Errorf("enigma2ServiceURL(%q) = %q, want %q", uri, got, want)
+		}
+	}
+}
+
+func TestWriteEnigma2_Golden(t *testing.T) {
+	p := Playlist{Entries: goldenEntries(), GroupOrder: map[string]int{"us movies": 0}}
+	dir := t.TempDir()
+	if err := writeEnigma2(dir, p.generateOutput(SplitByGroup)); err != nil {
+		t.Fatal(err)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// NBA, US Movies and the entry without group
+	if len(files) != 4 {
+		t.Errorf("got %d files, want 3 bouquets and the index", len(files))
+	}
+	checkGolden(t, filepath.Join(dir, "bouquets.tv"), "enigma2/bouquets.tv")
+	checkGolden(t, filepath.Join(dir, "userbouquet.iptv_us_movies.tv"), "enigma2/userbouquet.iptv_us_movies.tv")
+}
+
+func TestWriteEnigma2_SameFileName(t *testing.T) {
+	p := Playlist{Entries: []*PlaylistEntry{
+		newTestEntry("HBO", map[string]string{"group-title": "US Movies"}, nil),
+		newTestEntry("AMC", map[string]string{"group-title": "US/Movies"}, nil),
+	}}
+	dir := t.TempDir()
+	if err := writeEnigma2(dir, p.generateOutput(SplitByGroup)); err != nil {
+		t.Fatal(err)
+	}
+	index, err := os.ReadFile(filepath.Join(dir, "bouquets.tv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"userbouquet.iptv_us_movies.tv", "userbouquet.iptv_us_movies_2.tv"} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Errorf("missing bouquet: %v", err)
+		}
+		if strings.Count(string(index), `"`+file+`"`) != 1 {
+			t.Errorf("%s not listed once in bouquets.tv:\n%s", file, index)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// "No event" fill before and after each game in the generated guide
+const epgFillDuration = 12 * time.Hour
+
+// XMLTV time format
+const xmltvTimeFormat = "20060102150405 -0700"
+
+type xmltvTV struct {
+	XMLName           struct{}         `xml:"tv"`
+	GeneratorInfoName string           `xml:"generator-info-name,attr"`
+	Channels          []xmltvChannel   `xml:"channel"`
+	Programmes        []xmltvProgramme `xml:"programme"`
+}
+
+type xmltvChannel struct {
+	ID          string      `xml:"id,attr"`
+	DisplayName []xmltvText `xml:"display-name"`
+	Icon        *xmltvIcon  `xml:"icon,omitempty"`
+}
+
+type xmltvIcon struct {
+	Src string `xml:"src,attr"`
+}
+
+type xmltvText struct {
+	Lang string `xml:"lang,attr,omitempty"`
+	Text string `xml:",chardata"`
+}
+
+type xmltvProgramme struct {
+	Start    string      `xml:"start,attr"`
+	Stop     string      `xml:"stop,attr"`
+	Channel  string      `xml:"channel,attr"`
+	Title    []xmltvText `xml:"title"`
+	Desc     []xmltvText `xml:"desc,omitempty"`
+	Category []xmltvText `xml:"category,omitempty"`
+}
+
+// epgChannelName is the stable part of an entry's name: the channel of a game ("NBA 09"), otherwise the title
+// without tags.
+func epgChannelName(e *PlaylistEntry) string {
+	if m := e.Info.NBAMatch; m != nil && m.Channel != "" {
+		return m.Channel
+	}
+	if name := e.Info.TvgName(); name != "" && e.Info.StartTimeLocal == nil {
+		return name
+	}
+	return e.Info.TitleCopy
+}
+
+// assignTvgIDs gives a tvg-id to the entries without one, made from their channel name so it survives the
+// daily title changes of event channels ("nba-09.iptv").
+func (p *Playlist) assignTvgIDs() {
+	used := make(map[string]string)
+	for _, e := range p.Entries {
+		if id := e.Info.TvgID(); id != "" {
+			used[strings.ToLower(id)] = e.URI
+		}
+	}
+	for _, e := range p.Entries {
+		if e.Info.TvgID() != "" {
+			continue
+		}
+		slug := strings.ReplaceAll(normalizeChannelTitle(epgChannelName(e)), " ", "-")
+		if slug == "" {
+			slug = "channel"
+		}
+		id := slug + ".iptv"
+		// Another stream with the same name gets its own id, the same stream keeps the shared one
+		for n := 2; used[id] != "" && used[id] != e.URI; n++ {
+			id = fmt.Sprintf("%s-%d.iptv", slug, n)
+		}
+		used[id] = e.URI
+		e.Info.SetTvgID(id)
+	}
+}
+
+// epgGame is a game shown on a guide channel.
+type epgGame struct {
+	start, stop time.Time
+	match       *NBAMatch
+}
+
+// buildEPG makes a guide with a channel per tvg-id and, for every parsed game, a programme from its start time,
+// with "No event" before, between and after the games of a channel.
+func buildEPG(entries []*PlaylistEntry) xmltvTV {
+	tv := xmltvTV{GeneratorInfoName: "iptv-m3u-enhancer"}
+	games := make(map[string][]epgGame)
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		id := e.Info.TvgID()
+		if id == "" {
+			continue
+		}
+		if !seen[id] {
+			seen[id] = true
+			channel := xmltvChannel{ID: id, DisplayName: []xmltvText{{Text: epgChannelName(e)}}}
+			if logo := e.Info.TvgLogo(); logo != "" {
+				channel.Icon = &xmltvIcon{Src: logo}
+			}
+			tv.Channels = append(tv.Channels, channel)
+		}
+		if e.Info.NBAMatch == nil || e.Info.StartTimeLocal == nil {
+			continue
+		}
+		start := *e.Info.StartTimeLocal
+		duplicate := false
+		for _, g := range games[id] {
+			duplicate = duplicate || g.start.Equal(start)
+		}
+		if !duplicate {
+			games[id] = append(games[id], epgGame{start: start, stop: start.Add(gameDuration), match: e.Info.NBAMatch})
+		}
+	}
+	for _, channel := range tv.Channels {
+		channelGames := games[channel.ID]
+		sort.Slice(channelGames, func(i, j int) bool { return channelGames[i].start.Before(channelGames[j].start) })
+		for i, g := range channelGames {
+			fillFrom := g.start.Add(-epgFillDuration)
+			if i > 0 {
+				fillFrom = channelGames[i-1].stop
+			}
+			if fillFrom.Before(g.start) {
+				tv.Programmes = append(tv.Programmes, xmltvNoEvent(channel.ID, fillFrom, g.start))
+			}
+			// Overlapping games end when the next one starts
+			if i+1 < len(channelGames) && channelGames[i+1].start.Before(g.stop) {
+				g.stop = channelGames[i+1].start
+				channelGames[i].stop = g.stop
+			}
+			tv.Programmes = append(tv.Programmes, xmltvGame(channel.ID, g))
+		}
+		if n := len(channelGames); n > 0 {
+			last := channelGames[n-1].stop
+			tv.Programmes = append(tv.Programmes, xmltvNoEvent(channel.ID, last, last.Add(epgFillDuration)))
+		}
+	}
+	return tv
+}
+
+func xmltvGame(channel string, g epgGame) xmltvProgramme {
+	// Away team first as in the calendar, or the teams as listed in the title
+	first, second := g.match.Teams()
+	desc := fmt.Sprintf("%s vs %s", first.Name, second.Name)
+	if g.match.StreamType != "" {
+		desc += " (" + g.match.StreamType + ")"
+	}
+	return xmltvProgramme{
+		Start:    g.start.UTC().Format(xmltvTimeFormat),
+		Stop:     g.stop.UTC().Format(xmltvTimeFormat),
+		Channel:  channel,
+		Title:    []xmltvText{{Lang: "en", Text: fmt.Sprintf("%s vs %s", first.TeamName, second.TeamName)}},
+		Desc:     []xmltvText{{Lang: "en", Text: desc}},
+		Category: []xmltvText{{Lang: "en", Text: "Sports"}, {Lang: "en", Text: "Basketball"}},
+	}
+}
+
+func xmltvNoEvent(channel string, start, stop time.Time) xmltvProgramme {
+	return xmltvProgramme{
+		Start:   start.UTC().Format(xmltvTimeFormat),
+		Stop:    stop.UTC().Format(xmltvTimeFormat),
+		Channel: channel,
+		Title:   []xmltvText{{Lang: "en", Text: "No event"}},
+	}
+}
+
+// writeEPG writes the XMLTV guide of the entries.
+func writeEPG(outPath string, entries []*PlaylistEntry) error {
+	return writeXMLFile(outPath, buildEPG(entries))
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildEPG(t *testing.T) {
+	p := Playlist{}
+	for _, e := range []struct{ title, id string }{
+		{"NBA 09: Sacramento Kings @ Miami Heat | Home Stream | 12/06/2025 8:00 PM ET", ""},
+		{"NBA 09: Boston Celtics @ Los Angeles Lakers | Home Stream | 12/06/2025 11:00 PM ET", ""},
+		{"NBA 09: Phoenix Suns @ Denver Nuggets | Home Stream | 12/07/2025 12:30 AM ET", ""},
+		{"NBA 10: Sacramento Kings @ Miami Heat | Away Stream | 12/06/2025 8:00 PM ET", "nba10.us"},
+		{"USA  ESPN HD", ""},
+	} {
+		p.Entries = append(p.Entries, newTestEntry(e.title, map[string]string{"group-title": "NBA", "tvg-id": e.id}, nil))
+	}
+	// The "NBA 09" entries are the same stream
+	p.Entries[1].URI = p.Entries[0].URI
+	p.Entries[2].URI = p.Entries[0].URI
+	p.processNBAEntries(2025)
+	p.assignTvgIDs()
+
+	wantIDs := []string{"nba-09.iptv", "nba-09.iptv", "nba-09.iptv", "nba10.us", "espn.iptv"}
+	for i, e := range p.Entries {
+		if e.Info.TvgID() != wantIDs[i] {
+			t.Errorf("#%d tvg-id = %q, want %q", i, e.Info.TvgID(), wantIDs[i])
+		}
+	}
+
+	tv := buildEPG(p.Entries)
+	if len(tv.Channels) != 3 {
+		t.Errorf("got %d channels, want 3", len(tv.Channels))
+	}
+	var got []string
+	for _, prog := range tv.Programmes {
+		if prog.Channel == "nba-09.iptv" {
+			got = append(got, prog.Title[0].Text+" "+prog.Start[:12]+"-"+prog.Stop[:12])
+		}
+	}
+	// Times in UTC (ET + 5h): 2h30 games at 01:00, 04:00 and 05:30, the second one cut when the third starts
+	want := []string{
+		"No event 202512061300-202512070100",
+		"Kings vs Heat 202512070100-202512070330",
+		"No event 202512070330-202512070400",
+		"Celtics vs Lakers 202512070400-202512070530",
+		"Suns vs Nuggets 202512070530-202512070800",
+		"No event 202512070800-202512072000",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("programmes:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestWriteFilteredM3U_TvgURL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.m3u")
+	if err := writeFilteredM3U(path, []*PlaylistEntry{newTestEntry("a", nil, nil)}, "http://example.com/epg.xml"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "#EXTM3U x-tvg-url=\"http://example.com/epg.xml\"\n") {
+		t.Errorf("header = %q", strings.SplitN(string(data), "\n", 2)[0])
+	}
+}
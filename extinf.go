@@ -0,0 +1,172 @@
+package main
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ExtInf struct {
+	Duration   int
+	Title      string
+	Attributes map[string]string
+	//Raw        string
+	// Parsed times (when present in title). UTC source converted to local as well.
+	StartTimeLocal *time.Time
+	TitleCopy      string
+	// Game involving one of the favorite teams (see --favorites)
+	Favorite bool
+	// Parsed game (see --nba), nil for other entries
+	NBAMatch *NBAMatch
+
+	// Attribute keys in insertion order, so entries are written back the way they were read
+	attrOrder []string
+	// Attributes computed from the entry rather than read, left out of the output (see SetDerivedAttr)
+	derived map[string]bool
+}
+
+// Attribute keys are case-insensitive, they are stored lowercased like parseEXTINF does.
+func attrKey(key string) string {
+	return strings.ToLower(key)
+}
+
+func (e *ExtInf) GetAttr(key string) string {
+	if e.Attributes == nil {
+		return ""
+	}
+	return e.Attributes[attrKey(key)]
+}
+
+func (e *ExtInf) HasAttr(key string) bool {
+	if e.Attributes == nil {
+		return false
+	}
+	_, ok := e.Attributes[attrKey(key)]
+	return ok
+}
+
+func (e *ExtInf) SetAttr(key, value string) {
+	key = attrKey(key)
+	if e.Attributes == nil {
+		e.Attributes = make(map[string]string)
+	}
+	if _, ok := e.Attributes[key]; !ok {
+		e.attrOrder = append(e.attrOrder, key)
+	}
+	e.Attributes[key] = value
+	delete(e.derived, key)
+}
+
+// SetDerivedAttr sets an attribute computed from the title or group, like quality. It is available to filters,
+// sorting and splitting but only written with --annotate. An attribute read from the playlist stays written.
+func (e *ExtInf) SetDerivedAttr(key, value string) {
+	key = attrKey(key)
+	if e.HasAttr(key) && !e.derived[key] {
+		e.Attributes[key] = value
+		return
+	}
+	e.SetAttr(key, value)
+	if e.derived == nil {
+		e.derived = make(map[string]bool)
+	}
+	e.derived[key] = true
+}
+
+func (e *ExtInf) DeleteAttr(key string) {
+	key = attrKey(key)
+	if e.Attributes == nil {
+		return
+	}
+	delete(e.Attributes, key)
+	delete(e.derived, key)
+	// A new slice: value copies of the ExtInf share the backing array
+	if i := slices.Index(e.attrOrder, key); i >= 0 {
+		e.attrOrder = slices.Delete(slices.Clone(e.attrOrder), i, i+1)
+	}
+}
+
+// AttrKeys returns the attribute keys in insertion order. Keys added directly to the Attributes map
+// come last, sorted alphabetically.
+func (e *ExtInf) AttrKeys() []string {
+	keys := make([]string, 0, len(e.Attributes))
+	seen := make(map[string]bool, len(e.Attributes))
+	for _, key := range e.attrOrder {
+		if _, ok := e.Attributes[key]; ok && !seen[key] {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+	var extra []string
+	for key := range e.Attributes {
+		if !seen[key] {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	return append(keys, extra...)
+}
+
+// IsDerivedAttr reports whether key was set with SetDerivedAttr.
+func (e *ExtInf) IsDerivedAttr(key string) bool { return e.derived[key] }
+
+// OutputAttrKeys returns the keys of AttrKeys that are written, the derived attributes left out.
+func (e *ExtInf) OutputAttrKeys() []string {
+	keys := e.AttrKeys()
+	if len(e.derived) == 0 {
+		return keys
+	}
+	out := keys[:0]
+	for _, key := range keys {
+		if !e.derived[key] {
+			out = append(out, key)
+		}
+	}
+	return out
+}
+
+func (e *ExtInf) GroupTitle() string {
+	return e.GetAttr("group-title")
+}
+
+func (e *ExtInf) SetGroupTitle(groupTitle string) {
+	e.SetAttr("group-title", groupTitle)
+}
+
+func (e *ExtInf) TvgID() string {
+	return e.GetAttr("tvg-id")
+}
+
+func (e *ExtInf) SetTvgID(id string) {
+	e.SetAttr("tvg-id", id)
+}
+
+func (e *ExtInf) TvgName() string {
+	return e.GetAttr("tvg-name")
+}
+
+func (e *ExtInf) SetTvgName(name string) {
+	e.SetAttr("tvg-name", name)
+}
+
+func (e *ExtInf) TvgLogo() string {
+	return e.GetAttr("tvg-logo")
+}
+
+func (e *ExtInf) SetTvgLogo(logo string) {
+	e.SetAttr("tvg-logo", logo)
+}
+
+// TvgChno returns the channel number, ok is false when missing or not a number.
+func (e *ExtInf) TvgChno() (chno int, ok bool) {
+	chno, err := strconv.Atoi(strings.TrimSpace(e.GetAttr("tvg-chno")))
+	if err != nil {
+		return 0, false
+	}
+	return chno, true
+}
+
+func (e *ExtInf) SetTvgChno(chno int) {
+	e.SetAttr("tvg-chno", strconv.Itoa(chno))
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEXTINF_NoAttributes(t *testing.T) {
+	info, err := parseEXTINF("#EXTINF:-1,NBA 01: Pelicans vs Nets (Home) (12.06 5:00PM ET)")
+	if err != nil {
+		t.Fatalf("parseEXTINF: %v", err)
+	}
+	if len(info.AttrKeys()) != 0 {
+		t.Fatalf("expected no attributes, got %v", info.AttrKeys())
+	}
+	if info.HasAttr("group-title") || info.GroupTitle() != "" {
+		t.Errorf("unexpected group-title %q", info.GroupTitle())
+	}
+
+	info.SetGroupTitle("NBA")
+	info.SetTvgChno(7)
+	if info.GroupTitle() != "NBA" {
+		t.Errorf("GroupTitle() = %q, want %q", info.GroupTitle(), "NBA")
+	}
+	if chno, ok := info.TvgChno(); !ok || chno != 7 {
+		t.Errorf("TvgChno() = %d, %v, want 7, true", chno, ok)
+	}
+}
+
+func TestExtInf_SetAttrOnZeroValue(t *testing.T) {
+	entry := &PlaylistEntry{URI: "http://example.com/1"}
+	entry.Info.SetAttr("Tvg-ID", "nba01")
+	if got := entry.Info.TvgID(); got != "nba01" {
+		t.Fatalf("TvgID() = %q, want %q (attribute lost on nil map)", got, "nba01")
+	}
+	if !entry.Info.HasAttr("tvg-id") {
+		t.Error("HasAttr(tvg-id) = false, want true")
+	}
+
+	groups := parseTitle("NBA 09: Sacramento Kings @ Miami Heat | Home Stream | 12/06/2025 8:00 PM ET")
+	match := parseNBAMatch(groups, 2025)
+	if match == nil {
+		t.Fatal("parseNBAMatch returned nil")
+	}
+	var info ExtInf
+	info.SetNBAMatch(match)
+	if info.NBAMatchId() != "MIA-SAC" || info.NBAHome() != "MIA" || info.NBAAway() != "SAC" {
+		t.Errorf("SetNBAMatch on zero ExtInf: got id=%q home=%q away=%q", info.NBAMatchId(), info.NBAHome(), info.NBAAway())
+	}
+}
+
+func TestExtInf_KeysAndDelete(t *testing.T) {
+	info, err := parseEXTINF(`#EXTINF:-1 tvg-id="a" tvg-name="b" tvg-logo="" group-title="NBA",Title`)
+	if err != nil {
+		t.Fatalf("parseEXTINF: %v", err)
+	}
+	want := []string{"tvg-id", "tvg-name", "tvg-logo", "group-title"}
+	if got := info.AttrKeys(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("AttrKeys() = %v, want %v", got, want)
+	}
+
+	info.DeleteAttr("TVG-NAME")
+	info.SetTvgLogo("http://logo")
+	info.SetAttr("nba-match-id", "MIA-SAC")
+	want = []string{"tvg-id", "tvg-logo", "group-title", "nba-match-id"}
+	if got := info.AttrKeys(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("AttrKeys() after delete = %v, want %v", got, want)
+	}
+	if info.HasAttr("tvg-name") {
+		t.Error("HasAttr(tvg-name) = true after DeleteAttr")
+	}
+
+	entry := &PlaylistEntry{Info: info, URI: "http://example.com/1"}
+	wantLine := "#EXTINF:-1 tvg-id=\"a\" tvg-logo=\"http://logo\" group-title=\"NBA\" nba-match-id=\"MIA-SAC\",Title\nhttp://example.com/1\n"
+	if got := writeNewEntry(entry); got != wantLine {
+		t.Errorf("writeNewEntry() = %q, want %q", got, wantLine)
+	}
+}
+
+func TestExtInf_DeleteAttrKeepsCopies(t *testing.T) {
+	var e ExtInf
+	for _, key := range []string{"tvg-id", "tvg-name", "group-title"} {
+		e.SetAttr(key, "x")
+	}
+	c := e
+	c.Attributes = map[string]string{"tvg-id": "x", "tvg-name": "x", "group-title": "x"}
+	e.DeleteAttr("tvg-id")
+	if got := c.AttrKeys(); !reflect.DeepEqual(got, []string{"tvg-id", "tvg-name", "group-title"}) {
+		t.Errorf("copy keys = %v after deleting from the original", got)
+	}
+}
@@ -24,7 +24,8 @@ func sanitizeForFilename(s string) string {
 	return b.String()
 }
 
-func writeFilteredM3U(outPath string, entries []*PlaylistEntry) error {
+// writeFilteredM3U writes the entries, with the guide URL in the header when tvgURL is set.
+func writeFilteredM3U(outPath string, entries []*PlaylistEntry, tvgURL string) error {
 	var err error
 	var f *os.File
 	if err = os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
@@ -37,11 +38,15 @@ func writeFilteredM3U(outPath string, entries []*PlaylistEntry) error {
 	w := bufio.NewWriter(f)
 	defer w.Flush()
 
-	if _, err = w.WriteString("#EXTM3U\n"); err != nil {
+	header := "#EXTM3U"
+	if tvgURL != "" {
+		header += ` x-tvg-url="` + tvgURL + `"`
+	}
+	if _, err = w.WriteString(header + "\n"); err != nil {
 		return err
 	}
 	for _, e := range entries {
-		line := writeNewEntry(*e)
+		line := writeNewEntry(e)
 		if line == "" {
 			continue
 			// line = e.Info.Raw
@@ -2,4 +2,7 @@ module iptv-m3u-enhancer
 
 go 1.22
 
-require github.com/timematic/anytime v0.0.0-20250424004116-93a49dc8f85f
+require (
+	github.com/timematic/anytime v0.0.0-20250424004116-93a49dc8f85f
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func goldenEntries() []*PlaylistEntry {
+	entries := []*PlaylistEntry{
+		newTestEntry("NBA 09: Heat (MIA) vs Kings (SAC) > 20:00", map[string]string{"group-title": "NBA", "tvg-logo": "https://example.com/nba.png"}, nil),
+		newTestEntry("US: HBO", map[string]string{"group-title": "US Movies", "tvg-logo": "https://example.com/hbo.png"}, nil),
+		newTestEntry("Tom & Jerry <Classics>", map[string]string{"group-title": "US Movies"}, nil),
+		newTestEntry("No group", nil, nil),
+	}
+	entries[2].Info.Duration = 5400
+	for i, e := range entries {
+		e.URI = "http://example.com/stream/" + string(rune('1'+i)) + "?token=a&b=c"
+	}
+	return entries
+}
+
+// checkGolden compares a written file with testdata/<name>, rewriting it with -update:
+//
+//	go test -run Golden -update .
+func checkGolden(t *testing.T, path, name string) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("%s differs from %s:\n%s", path, golden, got)
+	}
+}
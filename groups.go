@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GroupPattern matches a group-title given as an exact name (case-insensitive), a glob ("US *") or
+// a regular expression between slashes ("/^(NBA|NFL)$/").
+type GroupPattern struct {
+	Text string
+	re   *regexp.Regexp
+}
+
+func newGroupPattern(text string) (GroupPattern, error) {
+	var expr string
+	switch {
+	case len(text) >= 2 && strings.HasPrefix(text, "/") && strings.HasSuffix(text, "/"):
+		expr = text[1 : len(text)-1]
+	case strings.ContainsAny(text, "*?"):
+		expr = "(?i)^" + globToRegexp(text) + "$"
+	default:
+		expr = "(?i)^" + regexp.QuoteMeta(text) + "$"
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return GroupPattern{}, fmt.Errorf("group pattern %q: %w", text, err)
+	}
+	return GroupPattern{Text: text, re: re}, nil
+}
+
+// globToRegexp translates * (any text) and ? (any character), everything else is literal.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	return b.String()
+}
+
+func (g GroupPattern) Match(groupTitle string) bool {
+	return g.re.MatchString(groupTitle)
+}
+
+// IsExact reports whether the pattern is a plain group name rather than a glob or regex.
+func (g GroupPattern) IsExact() bool {
+	return !strings.ContainsAny(g.Text, "*?") && !(len(g.Text) >= 2 && strings.HasPrefix(g.Text, "/") && strings.HasSuffix(g.Text, "/"))
+}
+
+// GroupSelector keeps the groups matching any Include pattern (all when empty) and none of the Exclude ones.
+type GroupSelector struct {
+	Include []GroupPattern
+	Exclude []GroupPattern
+}
+
+func newGroupSelector(include, exclude []string) (GroupSelector, error) {
+	var selector GroupSelector
+	for _, text := range include {
+		pattern, err := newGroupPattern(text)
+		if err != nil {
+			return GroupSelector{}, err
+		}
+		selector.Include = append(selector.Include, pattern)
+	}
+	for _, text := range exclude {
+		pattern, err := newGroupPattern(text)
+		if err != nil {
+			return GroupSelector{}, err
+		}
+		selector.Exclude = append(selector.Exclude, pattern)
+	}
+	return selector, nil
+}
+
+func (s GroupSelector) IsEmpty() bool {
+	return len(s.Include) == 0 && len(s.Exclude) == 0
+}
+
+func (s GroupSelector) Match(groupTitle string) bool {
+	for _, pattern := range s.Exclude {
+		if pattern.Match(groupTitle) {
+			return false
+		}
+	}
+	if len(s.Include) == 0 {
+		return true
+	}
+	for _, pattern := range s.Include {
+		if pattern.Match(groupTitle) {
+			return true
+		}
+	}
+	return false
+}
+
+// Name is the single exact group selected, used to name the output file, or "" otherwise.
+func (s GroupSelector) Name() string {
+	if len(s.Include) == 1 && s.Include[0].IsExact() {
+		return s.Include[0].Text
+	}
+	return ""
+}
+
+func (p *Playlist) filterGroups(selector GroupSelector) {
+	out := p.Entries[:0]
+	for _, e := range p.Entries {
+		if selector.Match(e.Info.GroupTitle()) {
+			out = append(out, e)
+		}
+	}
+	p.Entries = out
+}
+
+// GroupCount is the number of entries of a group-title, in order of first appearance.
+type GroupCount struct {
+	GroupTitle string
+	Count      int
+}
+
+func (p *Playlist) groupCounts() []GroupCount {
+	var counts []GroupCount
+	index := make(map[string]int)
+	for _, e := range p.Entries {
+		groupTitle := e.Info.GroupTitle()
+		i, ok := index[groupTitle]
+		if !ok {
+			i = len(counts)
+			index[groupTitle] = i
+			counts = append(counts, GroupCount{GroupTitle: groupTitle})
+		}
+		counts[i].Count++
+	}
+	return counts
+}
+
+// GroupMapping renames every group matching one of Groups to Name, merging them. The position of the mapping
+// in the config is the position of the group in the output.
+type GroupMapping struct {
+	Name   string   `json:"name" yaml:"name"`
+	Groups []string `json:"groups" yaml:"groups"`
+}
+
+type GroupMap struct {
+	names        []string
+	selectors    []GroupSelector
+	hideUnmapped bool
+}
+
+func newGroupMap(mappings []GroupMapping, hideUnmapped bool) (*GroupMap, error) {
+	m := &GroupMap{hideUnmapped: hideUnmapped}
+	for i, mapping := range mappings {
+		if strings.TrimSpace(mapping.Name) == "" {
+			return nil, fmt.Errorf("group mapping #%d: missing name", i+1)
+		}
+		groups := mapping.Groups
+		if len(groups) == 0 {
+			// A bare name only sets the position of that group
+			groups = []string{mapping.Name}
+		}
+		selector, err := newGroupSelector(groups, nil)
+		if err != nil {
+			return nil, fmt.Errorf("group mapping %q: %w", mapping.Name, err)
+		}
+		m.names = append(m.names, mapping.Name)
+		m.selectors = append(m.selectors, selector)
+	}
+	return m, nil
+}
+
+// Lookup returns the new name of a group, from the first mapping matching it.
+func (m *GroupMap) Lookup(groupTitle string) (string, bool) {
+	for i, selector := range m.selectors {
+		if selector.Match(groupTitle) {
+			return m.names[i], true
+		}
+	}
+	return "", false
+}
+
+// Order maps the lower-cased mapped group names to their position.
+func (m *GroupMap) Order() map[string]int {
+	order := make(map[string]int, len(m.names))
+	for i, name := range m.names {
+		if _, ok := order[strings.ToLower(name)]; !ok {
+			order[strings.ToLower(name)] = i
+		}
+	}
+	return order
+}
+
+// applyGroupMap renames and merges the mapped groups, drops the unmapped ones when asked, and records the group
+// order used by generateOutput and sortEntries.
+func (p *Playlist) applyGroupMap(m *GroupMap) {
+	out := p.Entries[:0]
+	for _, e := range p.Entries {
+		name, ok := m.Lookup(e.Info.GroupTitle())
+		if !ok && m.hideUnmapped {
+			continue
+		}
+		if ok {
+			e.Info.SetGroupTitle(name)
+		}
+		out = append(out, e)
+	}
+	p.Entries = out
+	p.GroupOrder = m.Order()
+}
+
+// groupRank is the position of a group in order, unmapped groups last.
+func groupRank(order map[string]int, groupTitle string) int {
+	if rank, ok := order[strings.ToLower(groupTitle)]; ok {
+		return rank
+	}
+	return len(order)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGroupSelector(t *testing.T) {
+	selector, err := newGroupSelector([]string{"nba", "US *", "/^(NFL|MOTOGP)$/"}, []string{"us movies", "XXX"})
+	if err != nil {
+		t.Fatalf("newGroupSelector: %v", err)
+	}
+	tests := map[string]bool{
+		"NBA":           true,
+		"NBA TV":        false,
+		"US Sports":     true,
+		"US Movies":     false,
+		"USA Premium":   false,
+		"NFL":           true,
+		"nfl":           false, // regex patterns are case-sensitive unless (?i) is given
+		"MOTOGP":        true,
+		"XXX":           false,
+		"United States": false,
+	}
+	for group, want := range tests {
+		if got := selector.Match(group); got != want {
+			t.Errorf("Match(%q) = %v, want %v", group, got, want)
+		}
+	}
+	if name := selector.Name(); name != "" {
+		t.Errorf("Name() = %q, want empty for several patterns", name)
+	}
+}
+
+func TestGroupSelector_ExcludeOnly(t *testing.T) {
+	selector, err := newGroupSelector(nil, []string{"XXX"})
+	if err != nil {
+		t.Fatalf("newGroupSelector: %v", err)
+	}
+	p := Playlist{Entries: []*PlaylistEntry{
+		newTestEntry("a", map[string]string{"group-title": "XXX"}, nil),
+		newTestEntry("b", map[string]string{"group-title": "SPORTS"}, nil),
+		newTestEntry("c", nil, nil),
+	}}
+	p.filterGroups(selector)
+	if len(p.Entries) != 2 || p.Entries[0].Info.Title != "b" || p.Entries[1].Info.Title != "c" {
+		t.Errorf("filterGroups kept %v", p.Entries)
+	}
+	if _, err := newGroupSelector([]string{"/(NBA/"}, nil); err == nil {
+		t.Error("expected error for invalid regex pattern")
+	}
+}
+
+func TestGroupMap(t *testing.T) {
+	mappings := []GroupMapping{
+		{Name: "NBA"},
+		{Name: "Sports", Groups: []string{"US Sports", "SPORTS", "USA Premium"}},
+	}
+	newPlaylist := func() Playlist {
+		return Playlist{Entries: []*PlaylistEntry{
+			newTestEntry("ESPN", map[string]string{"group-title": "USA Premium"}, nil),
+			newTestEntry("Sky Sports", map[string]string{"group-title": "SPORTS"}, nil),
+			newTestEntry("HBO", map[string]string{"group-title": "US Movies"}, nil),
+			newTestEntry("Heat vs Kings", map[string]string{"group-title": "nba"}, nil),
+			newTestEntry("Fox Sports", map[string]string{"group-title": "US Sports"}, nil),
+		}}
+	}
+
+	m, err := newGroupMap(mappings, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newPlaylist()
+	p.applyGroupMap(m)
+	out := p.generateOutput(SplitByNone)["ALL"]
+	out.sortEntries()
+	want := []string{"Heat vs Kings/NBA", "ESPN/Sports", "Fox Sports/Sports", "Sky Sports/Sports", "HBO/US Movies"}
+	for i, e := range out.Entries {
+		if got := e.Info.Title + "/" + e.Info.GroupTitle(); got != want[i] {
+			t.Errorf("#%d = %q, want %q", i, got, want[i])
+		}
+	}
+	if split := p.generateOutput(SplitByGroup); len(split) != 3 || len(split["SPORTS"].Entries) != 3 {
+		t.Errorf("merged groups not split together: %v", split)
+	}
+
+	m, err = newGroupMap(mappings, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p = newPlaylist()
+	p.applyGroupMap(m)
+	for _, e := range p.Entries {
+		if e.Info.GroupTitle() == "US Movies" {
+			t.Error("unmapped group not hidden")
+		}
+	}
+	if _, err := newGroupMap([]GroupMapping{{Groups: []string{"A"}}}, false); err == nil {
+		t.Error("expected error for mapping without name")
+	}
+}
+
+func TestGroupOrder_UnmappedGroupsTogether(t *testing.T) {
+	m, err := newGroupMap([]GroupMapping{{Name: "NBA"}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := Playlist{Entries: []*PlaylistEntry{
+		newTestEntry("CNN", map[string]string{"group-title": "Zeta"}, nil),
+		newTestEntry("BBC", map[string]string{"group-title": "Alpha"}, nil),
+		newTestEntry("ABC", map[string]string{"group-title": "Zeta"}, nil),
+		newTestEntry("NBA 01", map[string]string{"group-title": "NBA"}, nil),
+	}}
+	p.applyGroupMap(m)
+	titles := func(out PlaylistOutput) string {
+		var got []string
+		for _, e := range out.Entries {
+			got = append(got, e.Info.Title)
+		}
+		return strings.Join(got, ",")
+	}
+
+	out := p.generateOutput(SplitByNone)["ALL"]
+	out.Entries = slices.Clone(out.Entries)
+	out.sortEntries()
+	if got := titles(out); got != "NBA 01,BBC,ABC,CNN" {
+		t.Errorf("sorted = %s", got)
+	}
+	// Without sorting, only the groups move
+	out = p.generateOutput(SplitByNone)["ALL"]
+	out.Entries = slices.Clone(out.Entries)
+	out.sortByGroupOrder()
+	if got := titles(out); got != "NBA 01,BBC,CNN,ABC" {
+		t.Errorf("group order only = %s", got)
+	}
+}
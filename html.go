@@ -0,0 +1,137 @@
+package main
+
+import (
+	_ "embed"
+	"html/template"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	//go:embed web/games.html
+	gamesPageTemplate string
+	//go:embed web/games.css
+	gamesPageStyle string
+
+	gamesPage = template.Must(template.New("games").Parse(gamesPageTemplate))
+
+	// Stream schemes linked as is, html/template only trusts http(s) and mailto
+	streamSchemes = map[string]bool{"http": true, "https": true, "rtmp": true, "rtmps": true, "rtsp": true, "rtp": true, "udp": true, "mms": true, "srt": true}
+)
+
+type htmlStream struct {
+	Name    string
+	Type    string
+	Quality string
+	URI     string
+}
+
+// URL is the stream URI, trusted when its scheme is a streaming one.
+func (s htmlStream) URL() any {
+	if u, err := url.Parse(s.URI); err == nil && streamSchemes[strings.ToLower(u.Scheme)] {
+		return template.URL(s.URI)
+	}
+	return s.URI
+}
+
+// htmlGame is one game of the page and the streams showing it.
+type htmlGame struct {
+	MatchID string
+	// Away and home teams when At, the teams as listed in the title otherwise
+	First, Second NBAFranchise
+	At            bool
+	Start, End    time.Time
+	Live          bool
+	Ended         bool
+	Streams       []htmlStream
+}
+
+type htmlPage struct {
+	Title string
+	Now   time.Time
+	Style template.CSS
+	Games []htmlGame
+}
+
+// htmlGames groups the parsed games starting today or in the next 24 hours by match ID, ordered by start time
+// then match ID, the best streams first. Games over since midnight are kept and marked as ended.
+func htmlGames(entries []*PlaylistEntry, now time.Time) []htmlGame {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	until := now.Add(24 * time.Hour)
+	var games []htmlGame
+	index := make(map[string]int)
+	for _, e := range entries {
+		m := e.Info.NBAMatch
+		start := e.Info.StartTimeLocal
+		if m == nil || start == nil || start.Before(today) || !start.Before(until) {
+			continue
+		}
+		id := m.MatchId()
+		i, ok := index[id]
+		if !ok {
+			i = len(games)
+			index[id] = i
+			// Times are shown in the zone of now
+			begin := start.In(now.Location())
+			end := begin.Add(gameDuration)
+			first, second := m.Teams()
+			games = append(games, htmlGame{
+				MatchID: id,
+				First:   first,
+				Second:  second,
+				At:      m.HomeKnown(),
+				Start:   begin,
+				End:     end,
+				Live:    !now.Before(begin) && now.Before(end),
+				Ended:   !now.Before(end),
+			})
+		}
+		games[i].Streams = append(games[i].Streams, htmlStream{
+			Name:    m.Channel,
+			Type:    m.StreamType,
+			Quality: e.Info.GetAttr("quality"),
+			URI:     e.URI,
+		})
+	}
+	sort.SliceStable(games, func(i, j int) bool {
+		if !games[i].Start.Equal(games[j].Start) {
+			return games[i].Start.Before(games[j].Start)
+		}
+		return games[i].MatchID < games[j].MatchID
+	})
+	for _, g := range games {
+		sort.SliceStable(g.Streams, func(i, j int) bool {
+			if qi, qj := qualityRank(g.Streams[i].Quality), qualityRank(g.Streams[j].Quality); qi != qj {
+				return qi > qj
+			}
+			return compareNatural(g.Streams[i].Name, g.Streams[j].Name) < 0
+		})
+	}
+	return games
+}
+
+// writeGamesPage writes a standalone HTML page of today's games, as of now.
+func writeGamesPage(outPath string, entries []*PlaylistEntry, now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	page := htmlPage{
+		Title: "Games of " + now.Format("Monday, January 2"),
+		Now:   now,
+		Style: template.CSS(gamesPageStyle),
+		Games: htmlGames(entries, now),
+	}
+	if err := gamesPage.Execute(f, page); err != nil {
+		return err
+	}
+	return f.Close()
+}
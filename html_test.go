@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGamesPage(t *testing.T) {
+	p := Playlist{}
+	for _, e := range []struct{ title, uri, quality string }{
+		{"NBA 09: Sacramento Kings @ Miami Heat | Home Stream | 12/06/2025 8:00 PM ET", "http://example.com/9", ""},
+		{"NBA 10: Sacramento Kings @ Miami Heat | Away Stream | 12/06/2025 8:00 PM ET", "rtmp://example.com/10", "FHD"},
+		{"NBA 11: Boston Celtics @ Los Angeles Lakers | Home Stream | 12/06/2025 10:30 PM ET", "javascript:alert(1)", ""},
+		{"NBA 12: Boston Celtics @ Los Angeles Lakers | Home Stream | 12/05/2025 10:30 PM ET", "http://example.com/12", ""},
+		{"USA  ESPN HD", "http://example.com/espn", "HD"},
+	} {
+		entry := newTestEntry(e.title, map[string]string{"group-title": "NBA"}, nil)
+		entry.URI = e.uri
+		if e.quality != "" {
+			entry.Info.SetAttr("quality", e.quality)
+		}
+		p.Entries = append(p.Entries, entry)
+	}
+	p.processNBAEntries(2025)
+
+	// 02:00 UTC: the 01:00 UTC game is on, the 03:30 UTC game is next, yesterday's game is left out
+	now := time.Date(2025, 12, 7, 2, 0, 0, 0, time.UTC)
+	games := htmlGames(p.Entries, now)
+	if len(games) != 2 {
+		t.Fatalf("got %d games, want 2", len(games))
+	}
+	if games[0].MatchID != "MIA-SAC" || !games[0].Live || games[1].MatchID != "BOS-LAL" || games[1].Live || games[1].Ended {
+		t.Errorf("games = %+v", games)
+	}
+	if s := games[0].Streams; len(s) != 2 || s[0].Name != "NBA 10" || s[0].Type != "Away" || s[1].Name != "NBA 09" {
+		t.Errorf("streams not best quality first: %+v", s)
+	}
+
+	path := filepath.Join(t.TempDir(), "games.html")
+	if err := writeGamesPage(path, p.Entries, now); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(data)
+	for _, want := range []string{
+		"<title>Games of Sunday, December 7</title>",
+		`<img src="https://a.espncdn.com/i/teamlogos/nba/500/sac.png"`,
+		`<article class="game live"`,
+		`<time datetime="2025-12-07T01:00:00Z">01:00</time>`,
+		`<a href="rtmp://example.com/10">NBA 10 <span class="type">Away</span> <span class="quality">FHD</span></a>`,
+		`data-url="http://example.com/9"`,
+		"--live: #c8102e",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("page lacks %q", want)
+		}
+	}
+	if strings.Contains(html, "javascript:") {
+		t.Error("unsafe stream URL written as is")
+	}
+}
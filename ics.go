@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Length of a game, most titles have no end time
+const gameDuration = 2*time.Hour + 30*time.Minute
+
+// icsEvent is one game and the streams showing it.
+type icsEvent struct {
+	MatchID  string
+	Summary  string
+	Start    time.Time
+	Channels []string
+}
+
+// UID is stable across runs, so calendar clients update the event instead of adding another one. The date tells
+// apart the games of the same teams during a season.
+func (ev icsEvent) UID() string {
+	return fmt.Sprintf("%s-%s@iptv-m3u-enhancer", ev.MatchID, ev.Start.UTC().Format("20060102"))
+}
+
+// icsEvents groups the parsed games by match ID, ordered by start time then match ID.
+func icsEvents(entries []*PlaylistEntry) []icsEvent {
+	var events []icsEvent
+	index := make(map[string]int)
+	for _, e := range entries {
+		m := e.Info.NBAMatch
+		if m == nil || e.Info.StartTimeLocal == nil {
+			continue
+		}
+		id := m.MatchId()
+		i, ok := index[id]
+		if !ok {
+			i = len(events)
+			index[id] = i
+			events = append(events, icsEvent{
+				MatchID: id,
+				Summary: icsSummary(m),
+				Start:   *e.Info.StartTimeLocal,
+			})
+		}
+		channel := m.Channel
+		if m.StreamType != "" {
+			channel += " (" + m.StreamType + ")"
+		}
+		if !slices.Contains(events[i].Channels, channel) {
+			events[i].Channels = append(events[i].Channels, channel)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].Start.Equal(events[j].Start) {
+			return events[i].Start.Before(events[j].Start)
+		}
+		return events[i].MatchID < events[j].MatchID
+	})
+	return events
+}
+
+// icsSummary is "Away @ Home", or the teams as listed when the home team is unknown.
+func icsSummary(m *NBAMatch) string {
+	first, second := m.Teams()
+	if m.HomeKnown() {
+		return first.TeamName + " @ " + second.TeamName
+	}
+	return first.TeamName + " vs " + second.TeamName
+}
+
+var icsTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// icsLine folds a content line at 75 octets, as RFC 5545 requires, without splitting UTF-8 characters.
+func icsLine(w *bufio.Writer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space
+		limit = 74
+	}
+	w.WriteString(line + "\r\n")
+}
+
+// writeICS writes an iCalendar file with one event per game, stamped with now.
+func writeICS(outPath string, entries []*PlaylistEntry, now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	const utc = "20060102T150405Z"
+	icsLine(w, "BEGIN:VCALENDAR")
+	icsLine(w, "VERSION:2.0")
+	icsLine(w, "PRODID:-//iptv-m3u-enhancer//NBA games//EN")
+	icsLine(w, "CALSCALE:GREGORIAN")
+	icsLine(w, "X-WR-CALNAME:NBA games")
+	for _, ev := range icsEvents(entries) {
+		icsLine(w, "BEGIN:VEVENT")
+		icsLine(w, "UID:"+ev.UID())
+		icsLine(w, "DTSTAMP:"+now.UTC().Format(utc))
+		icsLine(w, "DTSTART:"+ev.Start.UTC().Format(utc))
+		icsLine(w, "DTEND:"+ev.Start.Add(gameDuration).UTC().Format(utc))
+		icsLine(w, "SUMMARY:"+icsTextEscaper.Replace(ev.Summary))
+		icsLine(w, "DESCRIPTION:"+icsTextEscaper.Replace("Streams:\n"+strings.Join(ev.Channels, "\n")))
+		icsLine(w, "END:VEVENT")
+	}
+	icsLine(w, "END:VCALENDAR")
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
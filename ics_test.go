@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteICS(t *testing.T) {
+	p := Playlist{}
+	for _, title := range []string{
+		"NBA 09: Kings vs Heat (Home) (12.06 8:00PM ET)",
+		"NBA 09: Sacramento Kings @ Miami Heat | Home Stream | 12/06/2025 8:00 PM ET",
+		"NBA 10: Sacramento Kings @ Miami Heat | Away Stream | 12/06/2025 8:00 PM ET",
+		"NBA 11: Boston Celtics @ Los Angeles Lakers | Home Stream | 12/06/2025 10:30 PM ET",
+		"NBA 12: Golden State Warriors vs Portland Trail Blazers | Portland Trail Blazers Broadcast Feed Alternate Commentary | 12/06/2025 10:30 PM ET",
+		"USA  ESPN HD",
+	} {
+		p.Entries = append(p.Entries, newTestEntry(title, map[string]string{"group-title": "NBA"}, nil))
+	}
+	p.processNBAEntries(2025)
+
+	events := icsEvents(p.Entries)
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want one per match", len(events))
+	}
+	if events[0].Summary != "Kings @ Heat" || events[1].Summary != "Celtics @ Lakers" || events[2].Summary != "Warriors vs Trail Blazers" {
+		t.Errorf("summaries = %q, %q, %q", events[0].Summary, events[1].Summary, events[2].Summary)
+	}
+	if got := strings.Join(events[0].Channels, "|"); got != "NBA 09 (Home)|NBA 10 (Away)" {
+		t.Errorf("channels = %q", got)
+	}
+	if uid := events[0].UID(); uid != "MIA-SAC-20251207@iptv-m3u-enhancer" {
+		t.Errorf("UID = %q", uid)
+	}
+
+	// The UID depends on the match and its date only, not on when the file is written
+	path := filepath.Join(t.TempDir(), "nba.ics")
+	now := time.Date(2025, 12, 6, 12, 0, 0, 0, time.UTC)
+	if err := writeICS(path, p.Entries, now); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ics := string(data)
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:MIA-SAC-20251207@iptv-m3u-enhancer\r\n",
+		"DTSTAMP:20251206T120000Z\r\n",
+		"DTSTART:20251207T010000Z\r\n",
+		"DTEND:20251207T033000Z\r\n",
+		"SUMMARY:Celtics @ Lakers\r\n",
+		`DESCRIPTION:Streams:\nNBA 09 (Home)\nNBA 10 (Away)` + "\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("missing %q in:\n%s", want, ics)
+		}
+	}
+	for _, line := range strings.Split(ics, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+	}
+}
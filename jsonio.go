@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Versioned schema of --format json and ndjson. Bump jsonSchemaVersion on incompatible changes.
+const (
+	jsonSchemaName    = "iptv-m3u-enhancer/playlist"
+	jsonSchemaVersion = 1
+)
+
+// Output formats, see --format
+const (
+	FormatM3U    = "m3u"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+// JSONPlaylist is the document written by --format json. With ndjson, the first line holds everything but the
+// entries, followed by one entry per line.
+type JSONPlaylist struct {
+	Schema      string           `json:"schema"`
+	Version     int              `json:"version"`
+	Entries     []JSONEntry      `json:"entries,omitempty"`
+	Diagnostics []JSONDiagnostic `json:"diagnostics,omitempty"`
+}
+
+type JSONEntry struct {
+	Title string `json:"title"`
+	// Title as read from the source playlist, before any rewriting
+	OriginalTitle string    `json:"original_title,omitempty"`
+	Duration      int       `json:"duration"`
+	URI           string    `json:"uri"`
+	Attributes    JSONAttrs `json:"attributes"`
+	// Keys of the attributes derived from the title and group, left out of the M3U output without --annotate
+	Derived  []string   `json:"derived,omitempty"`
+	Start    string     `json:"start,omitempty"` // RFC 3339
+	Favorite bool       `json:"favorite,omitempty"`
+	Match    *JSONMatch `json:"match,omitempty"`
+}
+
+type JSONMatch struct {
+	ID         string     `json:"id"`
+	Channel    string     `json:"channel,omitempty"`
+	StreamType string     `json:"stream_type,omitempty"`
+	Teams      []JSONTeam `json:"teams"`
+	// Only when known, see NBAMatch.HomeKnown
+	Home *JSONTeam `json:"home,omitempty"`
+	Away *JSONTeam `json:"away,omitempty"`
+}
+
+type JSONTeam struct {
+	Name       string  `json:"name"`
+	Acronym    string  `json:"acronym"`
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
+type JSONDiagnostic struct {
+	Title      string  `json:"title"`
+	Text       string  `json:"text"`
+	Team       string  `json:"team,omitempty"`
+	Confidence float64 `json:"confidence"`
+	Accepted   bool    `json:"accepted"`
+}
+
+// JSONAttrs is an attribute object that keeps the order of the keys, so a playlist read back is written the
+// way it was.
+type JSONAttrs struct {
+	Keys   []string
+	Values map[string]string
+}
+
+func (a JSONAttrs) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range a.Keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		v, _ := json.Marshal(a.Values[key])
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+func (a *JSONAttrs) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return fmt.Errorf("attributes: expected an object")
+	}
+	a.Values = make(map[string]string)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := tok.(string)
+		var value string
+		if err := dec.Decode(&value); err != nil {
+			return fmt.Errorf("attribute %q: %w", key, err)
+		}
+		if _, ok := a.Values[key]; !ok {
+			a.Keys = append(a.Keys, key)
+		}
+		a.Values[key] = value
+	}
+	return nil
+}
+
+func newJSONEntry(e *PlaylistEntry) JSONEntry {
+	je := JSONEntry{
+		Title:         e.Info.Title,
+		OriginalTitle: e.Info.TitleCopy,
+		Duration:      e.Info.Duration,
+		URI:           e.URI,
+		Attributes:    JSONAttrs{Keys: e.Info.AttrKeys(), Values: make(map[string]string)},
+		Favorite:      e.Info.Favorite,
+	}
+	for _, key := range je.Attributes.Keys {
+		je.Attributes.Values[key] = e.Info.GetAttr(key)
+		if e.Info.IsDerivedAttr(key) {
+			je.Derived = append(je.Derived, key)
+		}
+	}
+	if e.Info.StartTimeLocal != nil {
+		je.Start = e.Info.StartTimeLocal.Format(time.RFC3339)
+	}
+	if m := e.Info.NBAMatch; m != nil {
+		je.Match = &JSONMatch{
+			ID:         m.MatchId(),
+			Channel:    m.Channel,
+			StreamType: m.StreamType,
+			Teams: []JSONTeam{
+				{Name: m.Team1.Name, Acronym: m.Team1.Acronym, Confidence: m.Team1Confidence},
+				{Name: m.Team2.Name, Acronym: m.Team2.Acronym, Confidence: m.Team2Confidence},
+			},
+		}
+		if m.HomeKnown() {
+			je.Match.Home = &JSONTeam{Name: m.Home.Name, Acronym: m.Home.Acronym}
+			je.Match.Away = &JSONTeam{Name: m.Away.Name, Acronym: m.Away.Acronym}
+		}
+	}
+	return je
+}
+
+// entry rebuilds a playlist entry, the NBA match included.
+func (je JSONEntry) entry() (*PlaylistEntry, error) {
+	e := &PlaylistEntry{URI: je.URI}
+	e.Info.Title = je.Title
+	e.Info.TitleCopy = je.OriginalTitle
+	if e.Info.TitleCopy == "" {
+		e.Info.TitleCopy = je.Title
+	}
+	e.Info.Duration = je.Duration
+	e.Info.Favorite = je.Favorite
+	for _, key := range je.Attributes.Keys {
+		if slices.Contains(je.Derived, key) {
+			e.Info.SetDerivedAttr(key, je.Attributes.Values[key])
+		} else {
+			e.Info.SetAttr(key, je.Attributes.Values[key])
+		}
+	}
+	if je.Start != "" {
+		start, err := time.Parse(time.RFC3339, je.Start)
+		if err != nil {
+			return nil, fmt.Errorf("entry %q: start: %w", je.Title, err)
+		}
+		start = start.In(time.Local)
+		e.Info.StartTimeLocal = &start
+	}
+	if je.Match != nil && len(je.Match.Teams) == 2 {
+		team1 := findNBAFranchiseByAcronym(je.Match.Teams[0].Acronym)
+		team2 := findNBAFranchiseByAcronym(je.Match.Teams[1].Acronym)
+		if team1 != nil && team2 != nil {
+			m := &NBAMatch{
+				Channel:         je.Match.Channel,
+				Team1:           *team1,
+				Team2:           *team2,
+				StreamType:      je.Match.StreamType,
+				StartTime:       e.Info.StartTimeLocal,
+				Team1Confidence: je.Match.Teams[0].Confidence,
+				Team2Confidence: je.Match.Teams[1].Confidence,
+			}
+			if je.Match.Home != nil && je.Match.Away != nil {
+				if home, away := findNBAFranchiseByAcronym(je.Match.Home.Acronym), findNBAFranchiseByAcronym(je.Match.Away.Acronym); home != nil && away != nil {
+					m.Home, m.Away = *home, *away
+				}
+			}
+			e.Info.SetNBAMatch(m)
+		}
+	}
+	return e, nil
+}
+
+func newJSONDiagnostics(diagnostics []TeamMatchDiagnostic) []JSONDiagnostic {
+	var out []JSONDiagnostic
+	for _, d := range diagnostics {
+		jd := JSONDiagnostic{Title: d.Title, Text: d.Text, Confidence: d.Confidence, Accepted: d.Accepted}
+		if d.Franchise != nil {
+			jd.Team = d.Franchise.Acronym
+		}
+		out = append(out, jd)
+	}
+	return out
+}
+
+// writePlaylistJSON writes the entries as one JSON document, or as NDJSON when ndjson is set, with the
+// diagnostics of their titles.
+func writePlaylistJSON(outPath string, entries []*PlaylistEntry, diagnostics []TeamMatchDiagnostic, ndjson bool) error {
+	titles := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		titles[e.Info.TitleCopy] = true
+	}
+	var own []TeamMatchDiagnostic
+	for _, d := range diagnostics {
+		if titles[d.Title] {
+			own = append(own, d)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	doc := JSONPlaylist{Schema: jsonSchemaName, Version: jsonSchemaVersion, Diagnostics: newJSONDiagnostics(own)}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if ndjson {
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := enc.Encode(newJSONEntry(e)); err != nil {
+				return err
+			}
+		}
+	} else {
+		doc.Entries = make([]JSONEntry, 0, len(entries))
+		for _, e := range entries {
+			doc.Entries = append(doc.Entries, newJSONEntry(e))
+		}
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// parsePlaylistJSON reads a playlist written by writePlaylistJSON, as JSON or NDJSON.
+func parsePlaylistJSON(path string) (Playlist, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Playlist{}, err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(bufio.NewReader(f))
+	var doc JSONPlaylist
+	if err := dec.Decode(&doc); err != nil {
+		return Playlist{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if doc.Schema != jsonSchemaName {
+		return Playlist{}, fmt.Errorf("%s: unknown schema %q", path, doc.Schema)
+	}
+	if doc.Version > jsonSchemaVersion {
+		return Playlist{}, fmt.Errorf("%s: schema version %d is newer than %d", path, doc.Version, jsonSchemaVersion)
+	}
+	// NDJSON: the entries follow the header line
+	for {
+		var je JSONEntry
+		err := dec.Decode(&je)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Playlist{}, fmt.Errorf("%s: %w", path, err)
+		}
+		doc.Entries = append(doc.Entries, je)
+	}
+	playlist := Playlist{HeaderPresent: true}
+	for _, je := range doc.Entries {
+		e, err := je.entry()
+		if err != nil {
+			return Playlist{}, fmt.Errorf("%s: %w", path, err)
+		}
+		playlist.Entries = append(playlist.Entries, e)
+	}
+	return playlist, nil
+}
+
+// isJSONPlaylist tells JSON and NDJSON inputs apart from M3U by extension.
+func isJSONPlaylist(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".ndjson", ".jsonl":
+		return true
+	}
+	return false
+}
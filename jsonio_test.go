@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPlaylistJSON_RoundTrip(t *testing.T) {
+	titles := []string{
+		"NBA 09: Sacramento Kings @ Miami Heat | Home Stream | 12/06/2025 8:00 PM ET",
+		"USA  ESPN HD",
+	}
+	p := Playlist{}
+	for _, title := range titles {
+		p.Entries = append(p.Entries, newTestEntry(title, map[string]string{"tvg-id": "x.us", "group-title": "NBA", "custom-attr": "kept"}, nil))
+	}
+	diagnostics := p.processNBAEntries(2025)
+	p.Entries[0].Info.Favorite = true
+	// Exported, but still left out of the M3U line once read back
+	p.Entries[1].Info.SetDerivedAttr("tvg-country", "US")
+	if p.Entries[0].Info.NBAMatch == nil {
+		t.Fatal("NBA title not parsed")
+	}
+
+	for _, format := range []string{FormatJSON, FormatNDJSON} {
+		path := filepath.Join(t.TempDir(), "out."+format)
+		if err := writePlaylistJSON(path, p.Entries, diagnostics, format == FormatNDJSON); err != nil {
+			t.Fatal(err)
+		}
+		got, err := parsePlaylistJSON(path)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if len(got.Entries) != len(p.Entries) {
+			t.Fatalf("%s: got %d entries, want %d", format, len(got.Entries), len(p.Entries))
+		}
+		for i, e := range got.Entries {
+			want := p.Entries[i]
+			if e.Info.Title != want.Info.Title || e.Info.TitleCopy != want.Info.TitleCopy || e.URI != want.URI || e.Info.Favorite != want.Info.Favorite {
+				t.Errorf("%s #%d: got %+v, want %+v", format, i, e.Info, want.Info)
+			}
+			if writeNewEntry(e) != writeNewEntry(want) {
+				t.Errorf("%s #%d: M3U line %q, want %q", format, i, writeNewEntry(e), writeNewEntry(want))
+			}
+			if (e.Info.StartTimeLocal == nil) != (want.Info.StartTimeLocal == nil) ||
+				(e.Info.StartTimeLocal != nil && !e.Info.StartTimeLocal.Equal(*want.Info.StartTimeLocal)) {
+				t.Errorf("%s #%d: start %v, want %v", format, i, e.Info.StartTimeLocal, want.Info.StartTimeLocal)
+			}
+		}
+		m := got.Entries[0].Info.NBAMatch
+		if m == nil || m.MatchId() != "MIA-SAC" || m.Home.Acronym != "MIA" || m.StreamType == "" {
+			t.Errorf("%s: match not restored: %+v", format, m)
+		}
+		if espn := got.Entries[1].Info; espn.GetAttr("tvg-country") != "US" || !espn.IsDerivedAttr("tvg-country") {
+			t.Errorf("%s: derived tvg-country = %q, derived %v", format, espn.GetAttr("tvg-country"), espn.IsDerivedAttr("tvg-country"))
+		}
+	}
+}
+
+func TestParsePlaylistJSON_Schema(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"newer.json":   `{"schema":"iptv-m3u-enhancer/playlist","version":99,"entries":[]}`,
+		"other.ndjson": `{"schema":"something/else","version":1}`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := parsePlaylistJSON(path); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	start := time.Date(2025, 12, 6, 20, 0, 0, 0, time.UTC)
+	if got := newJSONEntry(newTestEntry("a", nil, &start)).Start; got != "2025-12-06T20:00:00Z" {
+		t.Errorf("start = %q, want RFC 3339", got)
+	}
+}
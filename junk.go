@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// JunkRule removes placeholder entries ("No Scheduled Event", "Coming Soon", ...). A rule matches when any of its
+// conditions match, restricted to Groups (all when empty) minus ExceptGroups.
+type JunkRule struct {
+	Name string `json:"name" yaml:"name"`
+	// Case-insensitive substrings of the title, or of Attr when set
+	Contains []string `json:"contains,omitempty" yaml:"contains,omitempty"`
+	// Regular expression on the title, or on Attr when set
+	Regex string `json:"regex,omitempty" yaml:"regex,omitempty"`
+	// Attribute tested instead of the title, e.g. "tvg-logo"
+	Attr string `json:"attr,omitempty" yaml:"attr,omitempty"`
+	// Matches entries whose URI has no host, like "http:///stream" or a bare path
+	EmptyURIHost bool `json:"empty_uri_host,omitempty" yaml:"empty_uri_host,omitempty"`
+	// Group patterns (see --group-title) the rule applies to, or is skipped for
+	Groups       []string `json:"groups,omitempty" yaml:"groups,omitempty"`
+	ExceptGroups []string `json:"except_groups,omitempty" yaml:"except_groups,omitempty"`
+
+	re       *regexp.Regexp
+	selector GroupSelector
+}
+
+func defaultJunkRules() []JunkRule {
+	return []JunkRule{
+		{Name: "no-event", Contains: []string{"no event", "offline", "no games", "no scheduled"}},
+		{Name: "coming-soon", Regex: `(?i)\bcoming soon\b|\bTBA\b|\bTBD\b`},
+		{Name: "separator", Regex: `^[\s\-=_*#|]+$`},
+		{Name: "placeholder-channel", Regex: `(?i)ⓧ\s*:?\s*(?:-+)?\s*$`},
+	}
+}
+
+func compileJunkRules(rules []JunkRule) ([]*JunkRule, error) {
+	compiled := make([]*JunkRule, 0, len(rules))
+	for i := range rules {
+		rule := rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		if rule.Regex != "" {
+			re, err := regexp.Compile(rule.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("junk rule %q: %w", rule.Name, err)
+			}
+			rule.re = re
+		}
+		if len(rule.Contains) == 0 && rule.re == nil && !rule.EmptyURIHost {
+			return nil, fmt.Errorf("junk rule %q: needs contains, regex or empty_uri_host", rule.Name)
+		}
+		selector, err := newGroupSelector(rule.Groups, rule.ExceptGroups)
+		if err != nil {
+			return nil, fmt.Errorf("junk rule %q: %w", rule.Name, err)
+		}
+		rule.selector = selector
+		compiled = append(compiled, &rule)
+	}
+	return compiled, nil
+}
+
+func (r *JunkRule) Match(e *PlaylistEntry) bool {
+	if !r.selector.Match(e.Info.GroupTitle()) {
+		return false
+	}
+	text := e.Info.Title
+	if r.Attr != "" {
+		text = e.Info.GetAttr(r.Attr)
+	}
+	textLower := strings.ToLower(text)
+	for _, sub := range r.Contains {
+		if strings.Contains(textLower, strings.ToLower(sub)) {
+			return true
+		}
+	}
+	if r.re != nil && r.re.MatchString(text) {
+		return true
+	}
+	if r.EmptyURIHost {
+		if u, err := url.Parse(e.URI); err != nil || u.Host == "" {
+			return true
+		}
+	}
+	return false
+}
+
+type JunkRuleCount struct {
+	Name    string
+	Removed int
+}
+
+// removeJunk drops the entries matching any rule, an entry is counted for the first rule it matches.
+func (p *Playlist) removeJunk(rules []*JunkRule) []JunkRuleCount {
+	counts := make([]JunkRuleCount, len(rules))
+	for i, rule := range rules {
+		counts[i].Name = rule.Name
+	}
+	out := p.Entries[:0]
+	for _, e := range p.Entries {
+		removed := false
+		for i, rule := range rules {
+			if rule.Match(e) {
+				counts[i].Removed++
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			out = append(out, e)
+		}
+	}
+	p.Entries = out
+	return counts
+}
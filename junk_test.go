@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRemoveJunk_DefaultRules(t *testing.T) {
+	rules, err := compileJunkRules(defaultJunkRules())
+	if err != nil {
+		t.Fatalf("compileJunkRules: %v", err)
+	}
+	keep := []string{
+		"USA | NBA 05ⓧ: Sacramento Kings vs Miami Heat | Sat 6th Dec 8:00PM ET",
+		"NBA 09: Kings vs Heat (Home) (12.06 8:00PM ET)",
+		"Tbilisi TV",
+		// No title is not a separator
+		"",
+	}
+	drop := []string{
+		"USA | NBA 10ⓧ: No Scheduled Event",
+		"NBA 12: Offline",
+		"PPV 03: Coming Soon",
+		"NFL 02: TBA",
+		"---",
+		"USA | NBA 11ⓧ:",
+	}
+	p := Playlist{}
+	for _, title := range append(keep, drop...) {
+		p.Entries = append(p.Entries, newTestEntry(title, map[string]string{"group-title": "NBA"}, nil))
+	}
+	counts := p.removeJunk(rules)
+	if len(p.Entries) != len(keep) {
+		for _, e := range p.Entries {
+			t.Logf("kept %q", e.Info.Title)
+		}
+		t.Fatalf("kept %d entries, want %d", len(p.Entries), len(keep))
+	}
+	total := 0
+	for _, c := range counts {
+		total += c.Removed
+	}
+	if total != len(drop) {
+		t.Errorf("rules removed %d entries in total, want %d: %v", total, len(drop), counts)
+	}
+}
+
+func TestRemoveJunk_AttributeAndGroupRules(t *testing.T) {
+	rules, err := compileJunkRules([]JunkRule{
+		{Name: "offline-logo", Attr: "tvg-logo", Contains: []string{"offline.png"}, ExceptGroups: []string{"NBA"}},
+		{Name: "no-host", EmptyURIHost: true},
+	})
+	if err != nil {
+		t.Fatalf("compileJunkRules: %v", err)
+	}
+	nba := newTestEntry("NBA 10", map[string]string{"group-title": "NBA", "tvg-logo": "https://logo/offline.png"}, nil)
+	sports := newTestEntry("ESPN", map[string]string{"group-title": "SPORTS", "tvg-logo": "https://logo/offline.png"}, nil)
+	noHost := newTestEntry("Local", nil, nil)
+	noHost.URI = "/streams/local.ts"
+	p := Playlist{Entries: []*PlaylistEntry{nba, sports, noHost}}
+
+	counts := p.removeJunk(rules)
+	if len(p.Entries) != 1 || p.Entries[0] != nba {
+		t.Errorf("expected only the NBA entry to be kept, got %d entries", len(p.Entries))
+	}
+	if counts[0].Removed != 1 || counts[1].Removed != 1 {
+		t.Errorf("unexpected counts %v", counts)
+	}
+
+	if _, err := compileJunkRules([]JunkRule{{Name: "empty"}}); err == nil {
+		t.Error("expected error for a rule without conditions")
+	}
+}
+
+func TestLoadConfig_JunkRulesReplaceDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"junk_rules": [{"name": "mine", "regex": "foo"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.JunkRules) != 1 || len(config.JunkRules[0].Contains) != 0 || config.JunkRules[0].Regex != "foo" {
+		t.Errorf("rules = %+v, want only the configured rule", config.JunkRules)
+	}
+	if config, err = loadConfig(""); err != nil || len(config.JunkRules) != len(defaultJunkRules()) {
+		t.Errorf("default rules not loaded: %+v, %v", config, err)
+	}
+}
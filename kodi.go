@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FormatKodi writes a Kodi library of .strm files for movies and series, see --format
+const FormatKodi = "kodi"
+
+// Characters most file systems refuse in names
+var kodiNameReplacer = strings.NewReplacer("/", "-", "\\", "-", ":", " -", "*", "", "?", "", "\"", "'", "<", "", ">", "", "|", "-")
+
+func kodiName(s string) string {
+	return strings.TrimRight(strings.TrimSpace(kodiNameReplacer.Replace(s)), ".")
+}
+
+// kodiPath is where the .strm file of an entry goes in the library, relative to its root, "" for entries that
+// are not movies or series seasons (live channels, 24/7 loops of a whole show).
+func kodiPath(e *PlaylistEntry) string {
+	switch e.Info.ContentType() {
+	case ContentMovie:
+		title := kodiName(e.Info.GetAttr("movie-title"))
+		if title == "" {
+			return ""
+		}
+		folder := title
+		if year := e.Info.GetAttr("movie-year"); year != "" {
+			folder = fmt.Sprintf("%s (%s)", title, year)
+		}
+		return filepath.Join(folder, title+".strm")
+	case ContentSeries, Content247:
+		show := kodiName(e.Info.GetAttr("series-name"))
+		season := e.Info.Season()
+		if show == "" || season == 0 {
+			return ""
+		}
+		// A 24/7 loop of a season is a single stream, stored as its first episode
+		episode := max(e.Info.Episode(), 1)
+		return filepath.Join(show, fmt.Sprintf("Season %02d", season), fmt.Sprintf("%s S%02dE%02d.strm", show, season, episode))
+	}
+	return ""
+}
+
+type kodiThumb struct {
+	Aspect string `xml:"aspect,attr,omitempty"`
+	URL    string `xml:",chardata"`
+}
+
+type kodiMovieNFO struct {
+	XMLName xml.Name   `xml:"movie"`
+	Title   string     `xml:"title"`
+	Year    int        `xml:"year,omitempty"`
+	Thumb   *kodiThumb `xml:"thumb,omitempty"`
+}
+
+type kodiShowNFO struct {
+	XMLName xml.Name   `xml:"tvshow"`
+	Title   string     `xml:"title"`
+	Thumb   *kodiThumb `xml:"thumb,omitempty"`
+}
+
+type kodiEpisodeNFO struct {
+	XMLName xml.Name   `xml:"episodedetails"`
+	Title   string     `xml:"title"`
+	Show    string     `xml:"showtitle"`
+	Season  int        `xml:"season"`
+	Episode int        `xml:"episode"`
+	Thumb   *kodiThumb `xml:"thumb,omitempty"`
+}
+
+func kodiLogo(e *PlaylistEntry, aspect string) *kodiThumb {
+	if logo := e.Info.TvgLogo(); logo != "" {
+		return &kodiThumb{Aspect: aspect, URL: logo}
+	}
+	return nil
+}
+
+// writeKodiLibrary writes Show/Season 06/Show S06E01.strm and Movie (Year)/Movie.strm files under dir, with .nfo
+// stubs carrying the logo when nfo is set. The first entry of a path wins. It returns the number of .strm
+// files written.
+func writeKodiLibrary(dir string, entries []*PlaylistEntry, nfo bool) (int, error) {
+	written := make(map[string]bool)
+	shows := make(map[string]bool)
+	for _, e := range entries {
+		rel := kodiPath(e)
+		if rel == "" || written[rel] {
+			continue
+		}
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return len(written), err
+		}
+		if err := os.WriteFile(path, []byte(e.URI+"\n"), 0o644); err != nil {
+			return len(written), err
+		}
+		written[rel] = true
+		if !nfo {
+			continue
+		}
+		nfoPath := strings.TrimSuffix(path, ".strm") + ".nfo"
+		var err error
+		if e.Info.ContentType() == ContentMovie {
+			year, _ := strconv.Atoi(e.Info.GetAttr("movie-year"))
+			err = writeXMLFile(nfoPath, kodiMovieNFO{Title: e.Info.GetAttr("movie-title"), Year: year, Thumb: kodiLogo(e, "poster")})
+		} else {
+			show := e.Info.GetAttr("series-name")
+			season, episode := e.Info.Season(), max(e.Info.Episode(), 1)
+			err = writeXMLFile(nfoPath, kodiEpisodeNFO{
+				Title:   fmt.Sprintf("%s S%02dE%02d", show, season, episode),
+				Show:    show,
+				Season:  season,
+				Episode: episode,
+				Thumb:   kodiLogo(e, "thumb"),
+			})
+			showDir := filepath.Dir(filepath.Dir(path))
+			if err == nil && !shows[showDir] {
+				shows[showDir] = true
+				err = writeXMLFile(filepath.Join(showDir, "tvshow.nfo"), kodiShowNFO{Title: show, Thumb: kodiLogo(e, "poster")})
+			}
+		}
+		if err != nil {
+			return len(written), err
+		}
+	}
+	return len(written), nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteKodiLibrary(t *testing.T) {
+	p := Playlist{}
+	add := func(title, group, uri, logo string) {
+		e := newTestEntry(title, map[string]string{"group-title": group, "tvg-logo": logo}, nil)
+		e.URI = uri
+		p.Entries = append(p.Entries, e)
+	}
+	add("24/7 The Sopranos S06 [VIP]", "24/7 Streams", "http://example.com/1", "http://example.com/sopranos.png")
+	add("Breaking Bad S02E05", "US Series", "http://example.com/series/2.mkv", "")
+	add("Heat: The Remake (1995)", "US Movies", "http://example.com/movie/3.mp4", "http://example.com/heat.png")
+	add("24/7 THE SOPRANOS", "24/7 Streams", "http://example.com/4", "")
+	add("US: HBO", "US Movies", "http://example.com/5", "")
+	p.classifyContents()
+
+	dir := t.TempDir()
+	written, err := writeKodiLibrary(dir, p.Entries, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != 3 {
+		t.Errorf("wrote %d .strm files, want 3 (live channels and whole-show loops left out)", written)
+	}
+	want := map[string]string{
+		"The Sopranos/Season 06/The Sopranos S06E01.strm": "http://example.com/1\n",
+		"Breaking Bad/Season 02/Breaking Bad S02E05.strm": "http://example.com/series/2.mkv\n",
+		"Heat - The Remake (1995)/Heat - The Remake.strm": "http://example.com/movie/3.mp4\n",
+	}
+	for rel, content := range want {
+		got, err := os.ReadFile(filepath.Join(dir, rel))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("%s = %q, want %q", rel, got, content)
+		}
+	}
+	for rel, logo := range map[string]string{
+		"The Sopranos/tvshow.nfo":                        "http://example.com/sopranos.png",
+		"Heat - The Remake (1995)/Heat - The Remake.nfo": "http://example.com/heat.png",
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, rel))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !strings.Contains(string(got), logo) {
+			t.Errorf("%s has no logo:\n%s", rel, got)
+		}
+	}
+}
@@ -8,40 +8,14 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
-type ExtInf struct {
-	Duration   int
-	Title      string
-	Attributes map[string]string
-	//Raw        string
-	// Parsed times (when present in title). UTC source converted to local as well.
-	StartTimeLocal *time.Time
-	TitleCopy      string
-}
-
-func (e ExtInf) GetAttr(key string) string {
-	if e.Attributes == nil {
-		return ""
-	}
-	return e.Attributes[key]
-}
-
-func (e ExtInf) SetAttr(key, value string) {
-	if e.Attributes == nil {
-		e.Attributes = make(map[string]string)
-	}
-	e.Attributes[key] = value
-}
-
-func (e ExtInf) GroupTitle() string {
-	return e.GetAttr("group-title")
-}
-
-func parseM3U(path string, strict bool, groupTitle string) (Playlist, error) {
+func parseM3U(path string, strict bool) (Playlist, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return Playlist{}, err
@@ -105,9 +79,6 @@ func parseM3U(path string, strict bool, groupTitle string) (Playlist, error) {
 
 		// Non-comment non-empty lines should be URIs
 		if currentEXTINF != nil {
-			if groupTitle != "" && !strings.EqualFold(currentEXTINF.GroupTitle(), groupTitle) {
-				continue
-			}
 			// start := parseTimesFromTitleV2(currentEXTINF.Title)
 			// if start != nil {
 			// 	currentEXTINF.StartTimeLocal = start
@@ -187,26 +158,37 @@ func parseEXTINF(line string) (ExtInf, error) {
 		return ExtInf{}, fmt.Errorf("invalid duration %q", durationStr)
 	}
 
-	attributes := make(map[string]string, 8)
-	// Prefer quoted key="value" matches
-	for _, m := range attrKVQuoted.FindAllStringSubmatch(attrsStr, -1) {
-		key := strings.ToLower(m[1])
-		val := m[2]
-		attributes[key] = val
+	ext := ExtInf{
+		Duration: dur,
+		Title:    title,
+	}
+	// Prefer quoted key="value" matches, then add plain key=value matches not already set (so quoted wins).
+	// Attributes are added in the order they appear in the line, so they are written back in the same order.
+	type attrMatch struct {
+		pos        int
+		key, value string
+	}
+	var matches []attrMatch
+	for _, m := range attrKVQuoted.FindAllStringSubmatchIndex(attrsStr, -1) {
+		matches = append(matches, attrMatch{m[0], strings.ToLower(attrsStr[m[2]:m[3]]), attrsStr[m[4]:m[5]]})
 	}
-	// Add plain key=value matches not already set (so quoted wins)
-	for _, m := range attrKVPlain.FindAllStringSubmatch(attrsStr, -1) {
-		key := strings.ToLower(m[1])
-		val := m[2]
-		if _, exists := attributes[key]; !exists {
-			attributes[key] = val
+	for _, m := range attrKVPlain.FindAllStringSubmatchIndex(attrsStr, -1) {
+		key := strings.ToLower(attrsStr[m[2]:m[3]])
+		exists := false
+		for _, q := range matches {
+			if q.key == key {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			matches = append(matches, attrMatch{m[0], key, attrsStr[m[4]:m[5]]})
 		}
 	}
-
-	ext := ExtInf{
-		Duration:   dur,
-		Title:      title,
-		Attributes: attributes,
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].pos < matches[j].pos })
+	ext.Attributes = make(map[string]string, len(matches))
+	for _, m := range matches {
+		ext.SetAttr(m.key, m.value)
 	}
 
 	return ext, nil
@@ -274,7 +256,9 @@ func extractFallbackYear(path string) int {
 
 func main() {
 	var (
-		flagGroupTitle string
+		flagGroupTitle stringListFlag
+		flagExclGroup  stringListFlag
+		flagStats      bool
 		flagOut        string
 		flagStrict     bool
 		flagStartTime  bool
@@ -282,36 +266,309 @@ func main() {
 		flagNBA        bool
 		flagGroupSplit bool
 		flagSort       bool
+		flagTeams      string
+		flagTeamThresh float64
+		flagTeamDiag   bool
+		flagConfig     string
+		flagFavorites  string
+		flagFavOnly    bool
+		flagWhere      string
+		flagKeepJunk   bool
+		flagDedupe     string
+		flagDedupeKeep string
+		flagDedupeRep  string
+		flagStripTags  bool
+		flagSplitBy    string
+		flagCountry    string
+		flagStripCtry  bool
+		flagParental   bool
+		flagAdultOut   string
+		flagNatural    bool
+		flagChno       bool
+		flagChnoBase   int
+		flagChnoMap    string
+		flagSortBy     string
+		flagFormat     string
+		flagColumns    string
+		flagKodiNFO    bool
+		flagICS        string
+		flagEPGOut     string
+		flagEPGURL     string
+		flagEPG        string
+		flagEPGMatch   string
+		flagHTML       bool
+		flagAnnotate   bool
 	)
-	flag.StringVar(&flagGroupTitle, "group-title", "", "Filter entries by group-title (case-insensitive).")
-	flag.StringVar(&flagOut, "out", "", "Output .m3u path. Defaults to '<input>.<group>.m3u' in the same directory.")
+	flag.Var(&flagGroupTitle, "group-title", "Keep entries of a group-title: exact name (case-insensitive), glob ('US *') or regex ('/^(NBA|NFL)$/'). Repeatable.")
+	flag.Var(&flagExclGroup, "exclude-group", "Drop entries of a group-title, same forms as --group-title. Repeatable.")
+	flag.BoolVar(&flagStats, "stats", false, "Print the number of entries per group-title of the whole playlist on stderr.")
+	flag.StringVar(&flagOut, "out", "", "Output directory, or output file when it has a playlist extension (.m3u, .xspf, .pls...). Defaults to the input directory.")
 	flag.BoolVar(&flagStrict, "strict", false, "Enable strict parsing and fail on malformed lines.")
 	flag.BoolVar(&flagStartTime, "start-time", false, "Filter entries with parsed start time.")
 	flag.BoolVar(&flagRecent, "recent", false, "Filter entries with start time prior to 6 hours ago or after 24 hours from now")
 	flag.BoolVar(&flagNBA, "nba", false, "Parse teams from title to improve sorting by match")
-	flag.BoolVar(&flagGroupSplit, "group-split", false, "Split entries into multiple playlists based on the group-title attribute.")
+	flag.BoolVar(&flagGroupSplit, "group-split", false, "Split entries into multiple playlists based on the group-title attribute (same as --split-by group).")
+	flag.StringVar(&flagSplitBy, "split-by", "", "Split entries into multiple playlists by group, country or type (live, 24/7, movie, series).")
 	flag.BoolVar(&flagSort, "sort", true, "Sort entries by start time (when present) then by nba-match-id (when present), then by title")
+	flag.StringVar(&flagTeams, "teams", "", "Team catalog (.json/.yaml) replacing the embedded catalog of the same league.")
+	flag.Float64Var(&flagTeamThresh, "team-threshold", minTeamConfidence, "Minimum confidence (0-1) to accept a fuzzy team name match.")
+	flag.BoolVar(&flagTeamDiag, "team-diagnostics", false, "List team names that were not an exact match (with --nba) on stderr.")
+	flag.StringVar(&flagConfig, "config", "", "Config file (.json/.yaml) with favorites and other settings.")
+	flag.StringVar(&flagFavorites, "favorites", "", "Comma-separated favorite teams (e.g. BOS,LAL) pinned to the top and marked (with --nba).")
+	flag.BoolVar(&flagFavOnly, "favorites-only", false, "Keep only games involving favorite teams (with --nba).")
+	flag.StringVar(&flagWhere, "where", "", "Keep entries matching an expression, e.g. 'group in (\"NBA\",\"US Sports\") and start within 6h'.")
+	flag.BoolVar(&flagKeepJunk, "keep-placeholders", false, "Keep placeholder entries (\"No Scheduled Event\", offline channels, ...) instead of applying the junk rules.")
+	flag.StringVar(&flagDedupe, "dedupe", "", "Remove duplicates by uri, uri-normalized, title-group and/or tvg-id (comma-separated, applied in order).")
+	flag.StringVar(&flagDedupeKeep, "dedupe-keep", DedupeKeepFirst, "Duplicate to keep: first, quality (best quality tag) or group:<pattern> (preferred group).")
+	flag.StringVar(&flagDedupeRep, "dedupe-report", "", "Write the collapsed duplicates to this file.")
+	flag.BoolVar(&flagStripTags, "strip-tags", false, "Remove quality, codec and variant tags (FHD, HEVC, [VIP], ...) from titles, they are kept as attributes.")
+	flag.StringVar(&flagCountry, "country", "", "Keep entries of these countries, comma-separated ISO codes (e.g. BR,US).")
+	flag.BoolVar(&flagStripCtry, "strip-country-prefix", false, "Remove country prefixes (\"USA |\", \"UK:\", \"BR\") from titles, the country is kept in tvg-country.")
+	flag.BoolVar(&flagParental, "parental", false, "Remove adult content by group, keywords, tvg-id and logo host (see parental in the config file).")
+	flag.StringVar(&flagAdultOut, "adult-out", "", "With --parental, write the removed adult entries to this playlist.")
+	flag.BoolVar(&flagNatural, "natural-sort", false, "Compare numbers in titles by value when sorting ('NBA 2' before 'NBA 10').")
+	flag.BoolVar(&flagChno, "chno", false, "Assign stable tvg-chno channel numbers per group.")
+	flag.IntVar(&flagChnoBase, "chno-base", 0, "First channel number of the groups without their own base in the config file (default 1).")
+	flag.StringVar(&flagChnoMap, "chno-map", "", "File keeping the assigned channel numbers across runs.")
+	flag.StringVar(&flagSortBy, "sort-by", "", "Sort keys, e.g. 'start,group,match,quality,title:natural,-chno' (- for descending). Replaces the default order.")
+	flag.StringVar(&flagFormat, "format", FormatM3U, "Output format: m3u, json, ndjson, csv, tsv, xspf, pls, enigma2 (bouquets) or kodi (.strm library). Defaults to the extension of --out. JSON, NDJSON, CSV and TSV files are also accepted as input.")
+	flag.StringVar(&flagColumns, "columns", "", "Comma-separated columns of the csv and tsv formats (fields like title, uri, start, or attribute names).")
+	flag.BoolVar(&flagKodiNFO, "kodi-nfo", false, "With --format kodi, write .nfo stubs with the logos next to the .strm files.")
+	flag.StringVar(&flagICS, "ics", "", "With --nba, also write the games to this iCalendar (.ics) file.")
+	flag.StringVar(&flagEPGOut, "epg-out", "", "Write an XMLTV guide of the entries (games with --nba) to this file, and point the playlists at it.")
+	flag.StringVar(&flagEPGURL, "epg-url", "", "x-tvg-url written in the playlists with --epg-out, when players fetch the guide elsewhere. Defaults to the guide path.")
+	flag.StringVar(&flagEPG, "epg", "", "XMLTV guide (path or URL, gzipped or not) annotating the entries with their current and next programmes by tvg-id.")
+	flag.StringVar(&flagEPGMatch, "epg-match", "", "With --epg, keep the channels whose current or next programme title matches this case-insensitive regex (e.g. NBA).")
+	flag.BoolVar(&flagHTML, "html", false, "With --nba, also write a page of today's games with their stream links next to the playlist (<name>.html).")
+	flag.BoolVar(&flagAnnotate, "annotate", false, "Write the attributes derived from titles and groups (quality, codec, variant, tvg-country, content-type...) into the output.")
 	flag.Parse()
 
 	args := flag.Args()
 	if len(args) < 1 {
-		fmt.Fprintln(os.Stderr, "usage: iptv-m3u-enhancer [--group-title \"<name>\"] [--out <path>] [--strict] [--start-time] [--recent] [--nba] <input.m3u>")
+		fmt.Fprintln(os.Stderr, "usage: iptv-m3u-enhancer [--group-title \"<name|glob|/regex/>\"]... [--exclude-group <pattern>]... [--out <path>] [--strict] [--start-time] [--recent] [--nba] [--teams <path>] [--config <path>] [--favorites <teams>] [--where <expr>] <input.m3u>")
+		os.Exit(2)
+	}
+	config, err := loadConfig(flagConfig)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "config error:", err)
+		os.Exit(1)
+	}
+	var where *WhereExpr
+	if flagWhere != "" {
+		if where, err = parseWhere(flagWhere); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	if flagICS != "" && !flagNBA {
+		fmt.Fprintln(os.Stderr, "--ics needs --nba")
+		os.Exit(2)
+	}
+	if flagHTML && !flagNBA {
+		fmt.Fprintln(os.Stderr, "--html needs --nba")
+		os.Exit(2)
+	}
+	var epgMatch *regexp.Regexp
+	if flagEPGMatch != "" {
+		if flagEPG == "" {
+			fmt.Fprintln(os.Stderr, "--epg-match needs --epg")
+			os.Exit(2)
+		}
+		if epgMatch, err = regexp.Compile("(?i)" + flagEPGMatch); err != nil {
+			fmt.Fprintln(os.Stderr, "epg-match error:", err)
+			os.Exit(2)
+		}
+	}
+	junkRules, err := compileJunkRules(config.JunkRules)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "config error:", err)
+		os.Exit(1)
+	}
+	var deduper *Deduper
+	if flagDedupe != "" {
+		if deduper, err = newDeduper(strings.Split(flagDedupe, ","), flagDedupeKeep); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	var parental *ParentalFilter
+	if flagParental {
+		if parental, err = newParentalFilter(config.Parental); err != nil {
+			fmt.Fprintln(os.Stderr, "config error:", err)
+			os.Exit(1)
+		}
+	}
+	var groupMap *GroupMap
+	if len(config.GroupMap) > 0 {
+		if groupMap, err = newGroupMap(config.GroupMap, config.HideUnmappedGroups); err != nil {
+			fmt.Fprintln(os.Stderr, "config error:", err)
+			os.Exit(1)
+		}
+	}
+	groupSelector, err := newGroupSelector(flagGroupTitle, flagExclGroup)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if flagGroupSplit && flagSplitBy == SplitByNone {
+		flagSplitBy = SplitByGroup
+	}
+	switch flagSplitBy {
+	case SplitByNone, SplitByGroup, SplitByCountry, SplitByType:
+	default:
+		fmt.Fprintf(os.Stderr, "unknown --split-by %q, expected %s, %s or %s\n", flagSplitBy, SplitByGroup, SplitByCountry, SplitByType)
 		os.Exit(2)
 	}
+	formatSet := false
+	flag.Visit(func(f *flag.Flag) { formatSet = formatSet || f.Name == "format" })
+	outFileFormat := formatExtensions[strings.ToLower(filepath.Ext(flagOut))]
+	if !formatSet && outFileFormat != "" {
+		flagFormat = outFileFormat
+	}
+	if !slices.Contains(outputFormats, flagFormat) {
+		fmt.Fprintf(os.Stderr, "unknown --format %q, expected one of %s\n", flagFormat, strings.Join(outputFormats, ", "))
+		os.Exit(2)
+	}
+	csvColumns := config.CSVColumns
+	if flagColumns != "" {
+		csvColumns = strings.Split(flagColumns, ",")
+	}
+	var sortSpec SortSpec
+	if flagSortBy != "" {
+		if sortSpec, err = parseSortSpec(flagSortBy); err != nil {
+			fmt.Fprintln(os.Stderr, "--sort-by:", err)
+			os.Exit(2)
+		}
+	}
+	minTeamConfidence = flagTeamThresh
+	if flagTeams != "" {
+		if err := loadTeamCatalogFile(flagTeams); err != nil {
+			fmt.Fprintln(os.Stderr, "teams error:", err)
+			os.Exit(1)
+		}
+	}
 	inPath := args[0]
-	playlist, err := parseM3U(inPath, flagStrict, flagGroupTitle)
+	var playlist Playlist
+	switch {
+	case isJSONPlaylist(inPath):
+		playlist, err = parsePlaylistJSON(inPath)
+	case isCSVPlaylist(inPath):
+		playlist, err = parsePlaylistCSV(inPath)
+	default:
+		playlist, err = parseM3U(inPath, flagStrict)
+	}
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "parse error:", err)
 		os.Exit(1)
 	}
 
+	// Adult content goes first, so nothing downstream (stats, splits, exports) ever sees it
+	if parental != nil {
+		blocked := playlist.removeBlocked(parental)
+		if flagAdultOut != "" {
+			if err := writeFilteredM3U(flagAdultOut, blocked, ""); err != nil {
+				fmt.Fprintln(os.Stderr, "write error:", err)
+				os.Exit(1)
+			}
+		}
+		if flagStats {
+			fmt.Fprintf(os.Stderr, "%5d removed by parental profile\n", len(blocked))
+		}
+	}
+
+	if flagStats {
+		for _, gc := range playlist.groupCounts() {
+			selected := ""
+			if !groupSelector.IsEmpty() && groupSelector.Match(gc.GroupTitle) {
+				selected = " (selected)"
+			}
+			fmt.Fprintf(os.Stderr, "%5d %s%s\n", gc.Count, gc.GroupTitle, selected)
+		}
+	}
+	playlist.filterGroups(groupSelector)
+
 	// Remove entries with undesired titles
-	playlist.filterRemoveWithTitle([]string{"no event", "offline", "no games", "no scheduled"})
+	if !flagKeepJunk {
+		for _, count := range playlist.removeJunk(junkRules) {
+			if flagStats && count.Removed > 0 {
+				fmt.Fprintf(os.Stderr, "%5d removed by junk rule %s\n", count.Removed, count.Name)
+			}
+		}
+	}
+
+	if deduper != nil {
+		sets := playlist.dedupe(deduper)
+		if flagStats {
+			removed := 0
+			for _, set := range sets {
+				removed += len(set.Dropped)
+			}
+			fmt.Fprintf(os.Stderr, "%5d removed as duplicates (%d sets)\n", removed, len(sets))
+		}
+		if flagDedupeRep != "" {
+			if err := writeDedupeReport(flagDedupeRep, sets); err != nil {
+				fmt.Fprintln(os.Stderr, "write error:", err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	// Quality, codec and variant tags as attributes
+	playlist.tagStreams(flagStripTags)
+	// Live, 24/7, movie or series, with series and movie details
+	playlist.classifyContents()
+
+	countryDetector := newCountryDetector(config.CountryPrefixes)
+	playlist.tagCountries(countryDetector, flagStripCtry)
+	if flagCountry != "" {
+		playlist.filterCountries(countryDetector, strings.Split(flagCountry, ","))
+	}
+
+	// Renamed groups from here on: selection, junk rules, dedupe and countries use the provider's names
+	if groupMap != nil {
+		playlist.applyGroupMap(groupMap)
+	}
 
 	fallbackYear := extractFallbackYear(inPath)
 	// Process entries with NBA new generic logic of splitting title into teams and start time
+	var diagnostics []TeamMatchDiagnostic
 	if flagNBA {
-		playlist.processNBAEntries(fallbackYear)
+		diagnostics = playlist.processNBAEntries(fallbackYear)
+		if flagTeamDiag {
+			for _, d := range diagnostics {
+				fmt.Fprintln(os.Stderr, "team match:", d)
+			}
+		}
+	}
+
+	if flagEPG != "" {
+		now := time.Now()
+		guide, err := loadGuide(flagEPG, playlist.guideChannels(), now)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "epg error:", err)
+			os.Exit(1)
+		}
+		playlist.annotateGuide(guide, now, epgMatch)
+	}
+
+	favoriteNames := config.Favorites
+	if flagFavorites != "" {
+		favoriteNames = strings.Split(flagFavorites, ",")
+	}
+	if flagNBA && len(favoriteNames) > 0 {
+		favorites, err := resolveFavoriteTeams(favoriteNames)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "favorites error:", err)
+			os.Exit(1)
+		}
+		playlist.markFavorites(favorites, config.FavoriteMarker)
+		if flagFavOnly {
+			playlist.filterFavorites()
+		}
+	}
+
+	if where != nil {
+		playlist.filterWhere(where, time.Now())
 	}
 
 	// Process entries based on start time information
@@ -336,6 +593,56 @@ func main() {
 		})
 	}
 
+	if flagChno {
+		chnoConfig := config.ChannelNumbers
+		if flagChnoBase != 0 {
+			chnoConfig.Base = flagChnoBase
+		}
+		if flagChnoMap != "" {
+			chnoConfig.MapFile = flagChnoMap
+		}
+		numbers := newChannelNumbers(chnoConfig.Base, chnoConfig.Groups)
+		if chnoConfig.MapFile != "" {
+			if err := numbers.load(chnoConfig.MapFile); err != nil {
+				fmt.Fprintln(os.Stderr, "channel numbers error:", err)
+				os.Exit(1)
+			}
+		}
+		playlist.assignChannelNumbers(numbers)
+		if chnoConfig.MapFile != "" {
+			if err := numbers.save(chnoConfig.MapFile); err != nil {
+				fmt.Fprintln(os.Stderr, "write error:", err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	if flagICS != "" {
+		if err := writeICS(flagICS, playlist.Entries, time.Now()); err != nil {
+			fmt.Fprintln(os.Stderr, "write error:", err)
+			os.Exit(1)
+		}
+	}
+
+	tvgURL := ""
+	if flagEPGOut != "" {
+		playlist.assignTvgIDs()
+		if err := writeEPG(flagEPGOut, playlist.Entries); err != nil {
+			fmt.Fprintln(os.Stderr, "write error:", err)
+			os.Exit(1)
+		}
+		tvgURL = flagEPGURL
+		if tvgURL == "" {
+			if tvgURL, err = filepath.Abs(flagEPGOut); err != nil {
+				tvgURL = flagEPGOut
+			}
+		}
+	}
+
+	if flagAnnotate {
+		playlist.annotate()
+	}
+
 	// Derive default output path if needed
 	outDirPath := filepath.Dir(inPath)
 	if flagOut != "" {
@@ -344,7 +651,73 @@ func main() {
 	outBaseName := filepath.Base(inPath)
 	outExt := filepath.Ext(outBaseName)
 	outName := strings.TrimSuffix(outBaseName, outExt)
-	if flagGroupSplit {
+	switch {
+	case flagFormat != FormatM3U:
+		outExt = "." + flagFormat
+	case isJSONPlaylist(inPath), isCSVPlaylist(inPath):
+		outExt = ".m3u"
+	}
+	// --out naming a file: written as is, or used as the name of the split directory
+	outFilePath := ""
+	if outFileFormat != "" {
+		outDirPath = filepath.Dir(flagOut)
+		if flagSplitBy == SplitByNone {
+			outFilePath = flagOut
+		} else {
+			outName = strings.TrimSuffix(filepath.Base(flagOut), filepath.Ext(flagOut))
+		}
+	}
+	sortOutput := func(output *PlaylistOutput) {
+		if flagSort || sortSpec != nil {
+			output.NaturalSort = flagNatural
+			output.SortSpec = sortSpec
+			output.sortEntries()
+		} else if output.GroupOrder != nil {
+			output.sortByGroupOrder()
+		}
+	}
+
+	// Before the writers, Kodi and Enigma2 return early
+	if flagHTML {
+		htmlPath := filepath.Join(outDirPath, outName+".html")
+		if outFileFormat != "" && flagSplitBy == SplitByNone {
+			htmlPath = strings.TrimSuffix(flagOut, filepath.Ext(flagOut)) + ".html"
+		}
+		if err := writeGamesPage(htmlPath, playlist.Entries, time.Now()); err != nil {
+			fmt.Fprintln(os.Stderr, "write error:", err)
+			os.Exit(1)
+		}
+	}
+
+	if flagFormat == FormatKodi {
+		// The library goes straight into the output directory, live entries are left out
+		written, err := writeKodiLibrary(outDirPath, playlist.Entries, flagKodiNFO)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "write error:", err)
+			os.Exit(1)
+		}
+		if flagStats {
+			fmt.Fprintf(os.Stderr, "%5d .strm files written\n", written)
+		}
+		return
+	}
+	if flagFormat == FormatEnigma2 {
+		// Bouquets go straight into the output directory, one per group unless split otherwise
+		if flagSplitBy == SplitByNone {
+			flagSplitBy = SplitByGroup
+		}
+		outputPlaylists := playlist.generateOutput(flagSplitBy)
+		for key, outputPlaylist := range outputPlaylists {
+			sortOutput(&outputPlaylist)
+			outputPlaylists[key] = outputPlaylist
+		}
+		if err := writeEnigma2(outDirPath, outputPlaylists); err != nil {
+			fmt.Fprintln(os.Stderr, "write error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if flagSplitBy != SplitByNone {
 		outDirPath = filepath.Join(outDirPath, outName)
 		if err := os.MkdirAll(outDirPath, 0o755); err != nil {
 			fmt.Fprintln(os.Stderr, "create directory error:", err)
@@ -352,33 +725,68 @@ func main() {
 		}
 	}
 
-	outputPlaylists := playlist.generateOutput(flagGroupSplit)
+	outputPlaylists := playlist.generateOutput(flagSplitBy)
 	for groupTitle, outputPlaylist := range outputPlaylists {
-		if flagSort {
-			outputPlaylist.sortEntries()
-		}
-		suffix := flagGroupTitle
-		if suffix == "" {
+		sortOutput(&outputPlaylist)
+		suffix := groupSelector.Name()
+		if suffix == "" || flagSplitBy != SplitByNone {
 			suffix = groupTitle
 		}
-		outFilePath := filepath.Join(outDirPath, fmt.Sprintf("%s %s%s", outName, sanitizeForFilename(suffix), outExt))
+		if outFileFormat == "" || flagSplitBy != SplitByNone {
+			outFilePath = filepath.Join(outDirPath, fmt.Sprintf("%s %s%s", outName, sanitizeForFilename(suffix), outExt))
+		}
 
-		if err := writeFilteredM3U(outFilePath, outputPlaylist.Entries); err != nil {
+		switch flagFormat {
+		case FormatJSON, FormatNDJSON:
+			err = writePlaylistJSON(outFilePath, outputPlaylist.Entries, diagnostics, flagFormat == FormatNDJSON)
+		case FormatCSV, FormatTSV:
+			err = writePlaylistCSV(outFilePath, outputPlaylist.Entries, csvColumns, flagFormat)
+		case FormatXSPF:
+			err = writeXSPF(outFilePath, outName+" "+suffix, outputPlaylist.Entries)
+		case FormatPLS:
+			err = writePLS(outFilePath, outputPlaylist.Entries)
+		default:
+			err = writeFilteredM3U(outFilePath, outputPlaylist.Entries, tvgURL)
+		}
+		if err != nil {
 			fmt.Fprintln(os.Stderr, "write error:", err)
 			os.Exit(1)
 		}
 	}
 }
 
-func writeNewEntry(e PlaylistEntry) string {
+// Output formats, and the format of each output file extension (see --format and --out)
+var (
+	outputFormats    = []string{FormatM3U, FormatJSON, FormatNDJSON, FormatCSV, FormatTSV, FormatXSPF, FormatPLS, FormatEnigma2, FormatKodi}
+	formatExtensions = map[string]string{
+		".m3u": FormatM3U, ".m3u8": FormatM3U,
+		".json": FormatJSON, ".ndjson": FormatNDJSON, ".jsonl": FormatNDJSON,
+		".csv": FormatCSV, ".tsv": FormatTSV,
+		".xspf": FormatXSPF, ".pls": FormatPLS,
+	}
+)
+
+// stringListFlag collects the values of a repeatable flag.
+type stringListFlag []string
+
+func (f *stringListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringListFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func writeNewEntry(e *PlaylistEntry) string {
 	strbExtinf := strings.Builder{}
 	strbExtinf.WriteString("#EXTINF:")
 	strbExtinf.WriteString(strconv.Itoa(e.Info.Duration))
-	for key, value := range e.Info.Attributes {
+	for _, key := range e.Info.OutputAttrKeys() {
 		strbExtinf.WriteString(" ")
 		strbExtinf.WriteString(key)
 		strbExtinf.WriteString("=\"")
-		strbExtinf.WriteString(value)
+		strbExtinf.WriteString(e.Info.GetAttr(key))
 		strbExtinf.WriteString("\"")
 	}
 	strbExtinf.WriteString(",")
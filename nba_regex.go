@@ -29,7 +29,13 @@ var (
 	//Example: NBA 02 : Brooklyn Nets @ Washington Wizards // UK Fri 2 Jan 11:45pm // ET Fri 2 Jan 6:45pm
 	reTitle4 = regexp.MustCompile(`(?i)(.*): (.*) (?:vs|x|@) (.*) \/\/ (.*) \/\/ (.*)`)
 
+	// More specific patterns first: reTitle1 would also match reTitle21 titles, leaving the stream type in team2
 	titleRegexes = []TitleRegex{
+		{
+			Regex:  reTitle21,
+			Format: "<channel name>: <team1> vs/x/@ <team2> | <stream type> Stream | (<start time>)",
+			Groups: []string{"channel", "team1", "team2", "stream type", "start time"},
+		},
 		{
 			Regex:  reTitle1,
 			Format: "<channel name>: <team1> vs/x/@ <team2> | <start time>",
@@ -40,11 +46,6 @@ var (
 			Format: "<channel name>: <team1> vs/x/@ <team2> (<stream type>) (<start time>)",
 			Groups: []string{"channel", "team1", "team2", "stream type", "start time"},
 		},
-		{
-			Regex:  reTitle21,
-			Format: "<channel name>: <team1> vs/x/@ <team2> | <stream type> Stream | (<start time>)",
-			Groups: []string{"channel", "team1", "team2", "stream type", "start time"},
-		},
 		{
 			Regex:  reTitle3,
 			Format: "<channel name>: <team1> vs/x/@ <team2> start:<start time> stop:<stop time>",
@@ -70,6 +71,8 @@ type NBATitleGroups struct {
 	Team2      string
 	StreamType string
 	StartTime  string
+	// Separator between teams, lowercased: "vs", "x" or "@"
+	Separator string
 }
 
 var nbaTitleGroupKeys = NBATitleGroups{
@@ -84,23 +87,36 @@ type NBAMatch struct {
 	Channel    string
 	Team1      NBAFranchise
 	Team2      NBAFranchise
+	Home       NBAFranchise
+	Away       NBAFranchise
 	StreamType string
 	StartTime  *time.Time
 	// StartTime2 *time.Time
 	// EndTime   *time.Time
+
+	// How sure we are about Team1 and Team2, see matchNBAFranchise
+	Team1Confidence float64
+	Team2Confidence float64
+}
+
+// MatchId identifies a game regardless of the order the provider listed the teams in.
+func (m *NBAMatch) MatchId() string {
+	a, b := m.Team1.Acronym, m.Team2.Acronym
+	if b < a {
+		a, b = b, a
+	}
+	return a + "-" + b
 }
 
 func parseTitle(title string) *NBATitleGroups {
 	for _, regex := range titleRegexes {
-		if m := regex.Regex.FindStringSubmatch(title); m != nil {
+		if m := regex.Regex.FindStringSubmatchIndex(title); m != nil {
 			mapGroups := make(map[string]string)
-			for i, group := range m {
-				if i > len(regex.Groups) {
-					break
-				}
-				if i > 0 {
-					mapGroups[regex.Groups[i-1]] = strings.TrimSpace(group)
+			for i := 1; i*2 < len(m) && i <= len(regex.Groups); i++ {
+				if m[i*2] < 0 {
+					continue
 				}
+				mapGroups[regex.Groups[i-1]] = strings.TrimSpace(title[m[i*2]:m[i*2+1]])
 			}
 			if mapGroups[nbaTitleGroupKeys.Team1] == "" ||
 				mapGroups[nbaTitleGroupKeys.Team2] == "" ||
@@ -108,12 +124,16 @@ func parseTitle(title string) *NBATitleGroups {
 				continue
 			}
 
+			// All patterns capture team1 and team2 as groups 2 and 3, the separator sits in between
+			separator := strings.ToLower(strings.TrimSpace(title[m[5]:m[6]]))
+
 			return &NBATitleGroups{
 				Channel:    mapGroups[nbaTitleGroupKeys.Channel],
 				Team1:      mapGroups[nbaTitleGroupKeys.Team1],
 				Team2:      mapGroups[nbaTitleGroupKeys.Team2],
 				StreamType: mapGroups[nbaTitleGroupKeys.StreamType],
 				StartTime:  mapGroups[nbaTitleGroupKeys.StartTime],
+				Separator:  separator,
 			}
 		}
 	}
@@ -121,9 +141,9 @@ func parseTitle(title string) *NBATitleGroups {
 }
 
 func parseNBAMatch(titleGroups *NBATitleGroups, fallbackYear int) *NBAMatch {
-	team1 := parseNBAFranchise(titleGroups.Team1)
-	team2 := parseNBAFranchise(titleGroups.Team2)
-	if team1 == nil || team2 == nil {
+	team1, confidence1 := matchNBAFranchise(titleGroups.Team1)
+	team2, confidence2 := matchNBAFranchise(titleGroups.Team2)
+	if confidence1 < minTeamConfidence || confidence2 < minTeamConfidence {
 		return nil
 	}
 
@@ -144,23 +164,44 @@ func parseNBAMatch(titleGroups *NBATitleGroups, fallbackYear int) *NBAMatch {
 		startTime = &t
 	}
 
+	// Only "Away @ Home" tells the home team: providers list "vs" and "x" teams in either order. processNBAEntries
+	// takes it from another stream of the game.
+	var home, away NBAFranchise
+	if titleGroups.Separator == "@" {
+		home, away = *team2, *team1
+	}
+
 	return &NBAMatch{
 		Channel:    titleGroups.Channel,
 		Team1:      *team1,
 		Team2:      *team2,
+		Home:       home,
+		Away:       away,
 		StreamType: titleGroups.StreamType,
 		StartTime:  startTime,
+
+		Team1Confidence: confidence1,
+		Team2Confidence: confidence2,
 	}
 }
 
+// HomeKnown reports whether the home and away teams are known.
+func (m *NBAMatch) HomeKnown() bool {
+	return m.Home.Acronym != ""
+}
+
+// Teams returns the away and home teams, or the teams as listed in the title when the home team is unknown.
+func (m *NBAMatch) Teams() (NBAFranchise, NBAFranchise) {
+	if m.HomeKnown() {
+		return m.Away, m.Home
+	}
+	return m.Team1, m.Team2
+}
+
 func parseNBAFranchise(name string) *NBAFranchise {
-	for _, franchise := range NBAFranchises {
-		if strings.Contains(name, string(franchise.Name)) ||
-			strings.Contains(name, franchise.TeamName) ||
-			strings.Contains(name, "("+franchise.Acronym+")") ||
-			(franchise.AcronymAlt != "" && strings.Contains(name, "("+franchise.AcronymAlt+")")) {
-			return &franchise
-		}
+	franchise, confidence := matchNBAFranchise(name)
+	if confidence < minTeamConfidence {
+		return nil
 	}
-	return nil
+	return franchise
 }
@@ -129,3 +129,70 @@ func TestReTitle4_MatchAndGroups(t *testing.T) {
 	}
 }
 
+func TestNBAMatchId_SameGameAcrossProviders(t *testing.T) {
+	// Six streams of the same game (Sacramento Kings at Miami Heat) as listed in playlist-example.m3u
+	titles := []string{
+		"NBA 05: Heat (MIA) x Kings (SAC) start:2025 12 07 00:50:00 stop:2025 12 07 04:50:00",
+		"NBA 09: Kings vs Heat (Home) (12.06 8:00PM ET)",
+		"NBA 10: Kings vs Heat (Away) (12.06 8:00PM ET)",
+		"NBA 09: Sacramento Kings @ Miami Heat | Home Stream | 12/06/2025 8:00 PM ET",
+		"NBA 10: Sacramento Kings @ Miami Heat | Away Stream | 12/06/2025 8:00 PM ET",
+		"USA | NBA 05ⓧ: Sacramento Kings vs Miami Heat | Sat 6th Dec 8:00PM ET",
+	}
+	for _, title := range titles {
+		groups := parseTitle(title)
+		if groups == nil {
+			t.Errorf("parseTitle(%q) = nil", title)
+			continue
+		}
+		match := parseNBAMatch(groups, 2025)
+		if match == nil {
+			t.Errorf("parseNBAMatch(%q) = nil", title)
+			continue
+		}
+		if got := match.MatchId(); got != "MIA-SAC" {
+			t.Errorf("MatchId(%q) = %q, want %q", title, got, "MIA-SAC")
+		}
+	}
+
+	// The "vs" and "x" streams take home and away from the "@" ones
+	p := Playlist{}
+	for _, title := range titles {
+		p.Entries = append(p.Entries, newTestEntry(title, map[string]string{"group-title": "NBA"}, nil))
+	}
+	p.processNBAEntries(2025)
+	for _, e := range p.Entries {
+		if e.Info.NBAHome() != "MIA" || e.Info.NBAAway() != "SAC" {
+			t.Errorf("%q: home/away = %s/%s, want MIA/SAC", e.Info.Title, e.Info.NBAHome(), e.Info.NBAAway())
+		}
+	}
+}
+
+func TestParseNBAMatch_HomeAway(t *testing.T) {
+	tests := []struct {
+		title     string
+		separator string
+		home      string
+		away      string
+	}{
+		{"NBA 09: Sacramento Kings @ Miami Heat | Home Stream | 12/06/2025 8:00 PM ET", "@", "MIA", "SAC"},
+		{"NBA 05: Heat (MIA) x Kings (SAC) start:2025 12 07 00:50:00 stop:2025 12 07 04:50:00", "x", "", ""},
+		{"NBA 27: Rockets vs Clippers (Home) (12.23 5:30PM ET)", "vs", "", ""},
+	}
+	for _, tt := range tests {
+		groups := parseTitle(tt.title)
+		if groups == nil {
+			t.Fatalf("parseTitle(%q) = nil", tt.title)
+		}
+		if groups.Separator != tt.separator {
+			t.Errorf("Separator(%q) = %q, want %q", tt.title, groups.Separator, tt.separator)
+		}
+		match := parseNBAMatch(groups, 2025)
+		if match == nil {
+			t.Fatalf("parseNBAMatch(%q) = nil", tt.title)
+		}
+		if match.Home.Acronym != tt.home || match.Away.Acronym != tt.away {
+			t.Errorf("%q: home/away = %s/%s, want %s/%s", tt.title, match.Home.Acronym, match.Away.Acronym, tt.home, tt.away)
+		}
+	}
+}
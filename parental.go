@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ParentalConfig lists what the parental profile (--parental) removes.
+type ParentalConfig struct {
+	// Group patterns (see --group-title) removed entirely
+	Groups []string `json:"groups" yaml:"groups"`
+	// Case-insensitive words or phrases searched in the title, tvg-name, tvg-id and logo file name.
+	// A leading or trailing * also matches inside words: "porn*" matches "PORNBOX", "*adult*" matches "playboytvadult".
+	Keywords []string `json:"keywords" yaml:"keywords"`
+	// Phrases ignored before looking for keywords, e.g. "adult swim", also with the words joined as in tvg-ids
+	AllowKeywords []string `json:"allow_keywords" yaml:"allow_keywords"`
+	// Exact tvg-id values (case-insensitive)
+	TvgIDs []string `json:"tvg_ids" yaml:"tvg_ids"`
+	// Logo hosts, subdomains included
+	LogoHosts []string `json:"logo_hosts" yaml:"logo_hosts"`
+}
+
+func defaultParentalConfig() ParentalConfig {
+	return ParentalConfig{
+		Groups: []string{"XXX*", "XXM*", "Adult*", "*18+*"},
+		Keywords: []string{
+			"xxx", "xxm", "*adult*", "*porn*", "brazzer*", "hustler", "*playboy*", "*penthouse*", "vixen", "*dorcel*",
+			"vivid tv", "evil angel", "reality kings", "mofos", "tushy", "milf*", "*erotic*", "18+",
+		},
+		AllowKeywords: []string{"adult swim"},
+	}
+}
+
+type ParentalFilter struct {
+	groups    GroupSelector
+	keywords  *regexp.Regexp
+	allow     *regexp.Regexp
+	tvgIDs    map[string]bool
+	logoHosts []string
+}
+
+func newParentalFilter(config ParentalConfig) (*ParentalFilter, error) {
+	f := &ParentalFilter{tvgIDs: make(map[string]bool)}
+	var err error
+	if len(config.Groups) > 0 {
+		// Any listed group is blocked: an include-only selector matches exactly those
+		if f.groups, err = newGroupSelector(config.Groups, nil); err != nil {
+			return nil, fmt.Errorf("parental: %w", err)
+		}
+	}
+	if f.keywords, err = keywordsRegexp(config.Keywords, false); err != nil {
+		return nil, fmt.Errorf("parental keywords: %w", err)
+	}
+	if f.allow, err = keywordsRegexp(config.AllowKeywords, true); err != nil {
+		return nil, fmt.Errorf("parental allow_keywords: %w", err)
+	}
+	for _, id := range config.TvgIDs {
+		f.tvgIDs[strings.ToLower(strings.TrimSpace(id))] = true
+	}
+	for _, host := range config.LogoHosts {
+		f.logoHosts = append(f.logoHosts, strings.ToLower(strings.TrimSpace(host)))
+	}
+	return f, nil
+}
+
+var reNotWord = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// keywordsRegexp builds one case-insensitive regexp matching any keyword as a whole word, nil when empty.
+// With joined, the words of a phrase may also be joined or split by punctuation as in tvg-ids and logo file names:
+// "adult swim" matches "AdultSwim.us" and "adult-swim.png".
+func keywordsRegexp(keywords []string, joined bool) (*regexp.Regexp, error) {
+	const word = `[\p{L}\p{N}]`
+	var alternatives []string
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		core := strings.Trim(keyword, "*")
+		if core == "" {
+			continue
+		}
+		prefix, suffix := `(?:^|[^\p{L}\p{N}])`, `(?:$|[^\p{L}\p{N}])`
+		if strings.HasPrefix(keyword, "*") {
+			prefix = ""
+		}
+		if strings.HasSuffix(keyword, "*") {
+			suffix = word + "*"
+		}
+		pattern := regexp.QuoteMeta(core)
+		if joined {
+			words := reNotWord.Split(core, -1)
+			for i, w := range words {
+				words[i] = regexp.QuoteMeta(w)
+			}
+			pattern = strings.Join(words, `[^\p{L}\p{N}]*`)
+		}
+		alternatives = append(alternatives, prefix+pattern+suffix)
+	}
+	if len(alternatives) == 0 {
+		return nil, nil
+	}
+	return regexp.Compile(`(?i)(?:` + strings.Join(alternatives, "|") + `)`)
+}
+
+func (f *ParentalFilter) hasKeyword(text string) bool {
+	if f.keywords == nil || text == "" {
+		return false
+	}
+	if f.allow != nil {
+		text = f.allow.ReplaceAllString(text, " ")
+	}
+	return f.keywords.MatchString(text)
+}
+
+// Blocked reports whether the entry is adult content.
+func (f *ParentalFilter) Blocked(e *PlaylistEntry) bool {
+	if len(f.groups.Include) > 0 && f.groups.Match(e.Info.GroupTitle()) {
+		return true
+	}
+	if f.tvgIDs[strings.ToLower(strings.TrimSpace(e.Info.TvgID()))] {
+		return true
+	}
+	if f.hasKeyword(e.Info.TitleCopy) || f.hasKeyword(e.Info.TvgName()) || f.hasKeyword(e.Info.TvgID()) {
+		return true
+	}
+	logo := e.Info.TvgLogo()
+	if logo == "" {
+		return false
+	}
+	u, err := url.Parse(logo)
+	if err != nil {
+		return f.hasKeyword(logo)
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, blocked := range f.logoHosts {
+		if host == blocked || strings.HasSuffix(host, "."+blocked) {
+			return true
+		}
+	}
+	return f.hasKeyword(u.Path[strings.LastIndex(u.Path, "/")+1:])
+}
+
+// removeBlocked drops the adult entries and returns them, in playlist order.
+func (p *Playlist) removeBlocked(f *ParentalFilter) []*PlaylistEntry {
+	var blocked []*PlaylistEntry
+	out := p.Entries[:0]
+	for _, e := range p.Entries {
+		if f.Blocked(e) {
+			blocked = append(blocked, e)
+			continue
+		}
+		out = append(out, e)
+	}
+	p.Entries = out
+	return blocked
+}
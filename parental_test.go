@@ -0,0 +1,87 @@
+package main
+
+import "testing"
+
+func TestParentalFilter_Blocked(t *testing.T) {
+	f, err := newParentalFilter(defaultParentalConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		title   string
+		attrs   map[string]string
+		blocked bool
+	}{
+		{"XXX: Hustler HD", map[string]string{"group-title": "XXX"}, true},
+		{"Some channel", map[string]string{"group-title": "Adult Channels"}, true},
+		// Leaks into other groups are caught by title and logo
+		{"XXM: PORNBOX NUBILE FILMS", map[string]string{"group-title": "SPORTS"}, true},
+		{"BRAZZERZ +3", map[string]string{"group-title": "United States"}, true},
+		{"Night TV", map[string]string{"group-title": "SPORTS", "tvg-logo": "https://logo.m3uassets.com/playboytvadult.png"}, true},
+		{"Adult Swim", map[string]string{"group-title": "United States"}, false},
+		{"US: Adult Swim HD", map[string]string{"group-title": "United States", "tvg-id": "AdultSwim.us", "tvg-logo": "https://logo.m3uassets.com/adultswim.png"}, false},
+		{"US: Cartoons", map[string]string{"group-title": "United States", "tvg-logo": "https://logo.m3uassets.com/adult-swim.png"}, false},
+		{"BR: GLOBO (P)", map[string]string{"group-title": "Brazil"}, false},
+		{"Sky Sport Bundesliga", map[string]string{"group-title": "Germany", "tvg-logo": "https://logo.m3uassets.com/skysport.png"}, false},
+	}
+	for _, tt := range tests {
+		if got := f.Blocked(newTestEntry(tt.title, tt.attrs, nil)); got != tt.blocked {
+			t.Errorf("%q %v: blocked = %v, want %v", tt.title, tt.attrs, got, tt.blocked)
+		}
+	}
+}
+
+func TestParentalFilter_Configured(t *testing.T) {
+	f, err := newParentalFilter(ParentalConfig{TvgIDs: []string{"Private.tv"}, LogoHosts: []string{"adult-logos.net"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Blocked(newTestEntry("A", map[string]string{"tvg-id": "private.TV"}, nil)) {
+		t.Error("tvg-id not blocked")
+	}
+	if !f.Blocked(newTestEntry("B", map[string]string{"tvg-logo": "http://cdn.adult-logos.net/b.png"}, nil)) {
+		t.Error("logo host not blocked")
+	}
+	// Only the configured rules apply
+	if f.Blocked(newTestEntry("XXX: Vivid TV", map[string]string{"group-title": "XXX"}, nil)) {
+		t.Error("default rules applied to a custom profile")
+	}
+}
+
+// No adult entry of the example playlist may reach any per-group playlist.
+func TestParentalFilter_NoBlockedEntryInGroupSplit(t *testing.T) {
+	playlist, err := parseM3U("playlist-example.m3u", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	adult := make(map[*PlaylistEntry]bool)
+	for _, e := range playlist.Entries {
+		if e.Info.GroupTitle() == "XXX" {
+			adult[e] = true
+		}
+	}
+	f, err := newParentalFilter(defaultParentalConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blocked := playlist.removeBlocked(f); len(blocked) != len(adult) {
+		t.Errorf("blocked %d entries, want the %d of the XXX group", len(blocked), len(adult))
+	}
+	// The adult group merged into a regular one would hide it from a check on split names
+	m, err := newGroupMap([]GroupMapping{{Name: "Movies", Groups: []string{"XXX", "* Movies"}}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	playlist.applyGroupMap(m)
+	splits := playlist.generateOutput(SplitByGroup)
+	if len(splits["MOVIES"].Entries) == 0 {
+		t.Fatal("no Movies split")
+	}
+	for key, output := range splits {
+		for _, e := range output.Entries {
+			if adult[e] {
+				t.Errorf("adult entry %q in split %q", e.Info.TitleCopy, key)
+			}
+		}
+	}
+}
@@ -15,52 +15,102 @@ type PlaylistEntry struct {
 type Playlist struct {
 	Entries       []*PlaylistEntry
 	HeaderPresent bool
+	// Position of the lower-cased group names, see applyGroupMap
+	GroupOrder map[string]int
 }
 
 type PlaylistOutput struct {
-	Entries []*PlaylistEntry
+	Entries    []*PlaylistEntry
+	GroupOrder map[string]int
+	// Compare numbers inside titles by value, "NBA 2" before "NBA 10" (see --natural-sort)
+	NaturalSort bool
+	// Keys of --sort-by, the default order when empty
+	SortSpec SortSpec
 }
 
-func (p *PlaylistOutput) sortEntries() {
-	sort.Slice(p.Entries, func(i, j int) bool {
-		a := p.Entries[i]
-		b := p.Entries[j]
-		at := a.Info.StartTimeLocal
-		bt := b.Info.StartTimeLocal
-		switch {
-		case at != nil && bt != nil:
-			if at.Equal(*bt) {
-				// On the same time, sort by nba-match-id if it exists
-				if a.Info.NBAMatchId() != "" && b.Info.NBAMatchId() != "" && a.Info.NBAMatchId() != b.Info.NBAMatchId() {
-					return a.Info.NBAMatchId() < b.Info.NBAMatchId()
-				}
+// compareGroupOrder compares the positions of two groups in the configured order, unmapped groups last and kept
+// together by name.
+func compareGroupOrder(order map[string]int, a, b string) int {
+	if order == nil {
+		return 0
+	}
+	if c := groupRank(order, a) - groupRank(order, b); c != 0 {
+		return c
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
 
-				// If no nba-match-id (or equal), sort by title (after colon when present), case-insensitive
-				_, ai, _ := strings.Cut(a.Info.Title, ":")
-				_, bi, _ := strings.Cut(b.Info.Title, ":")
-				ai = strings.ToLower(ai)
-				bi = strings.ToLower(bi)
-				if ai == bi {
-					return a.Info.Title < b.Info.Title
-				}
-				return ai < bi
-			}
-			return at.Before(*bt)
-		case at != nil && bt == nil:
-			// Items with time come first
-			return true
-		case at == nil && bt != nil:
-			return false
-		default:
-			// Both without time: sort by title
-			ai := strings.ToLower(a.Info.Title)
-			bi := strings.ToLower(b.Info.Title)
-			if ai == bi {
-				return a.Info.Title < b.Info.Title
+// sortByGroupOrder only puts the groups in the configured order, the entries of a group keep theirs. It is the
+// order of the output when sorting is off.
+func (p *PlaylistOutput) sortByGroupOrder() {
+	sort.SliceStable(p.Entries, func(i, j int) bool {
+		return compareGroupOrder(p.GroupOrder, p.Entries[i].Info.GroupTitle(), p.Entries[j].Info.GroupTitle()) < 0
+	})
+}
+
+// compareSeasons orders episodes of the same series by season, then episode.
+func compareSeasons(a, b *PlaylistEntry) int {
+	if a.Info.Season() != b.Info.Season() {
+		return a.Info.Season() - b.Info.Season()
+	}
+	return a.Info.Episode() - b.Info.Episode()
+}
+
+// compareText compares case-insensitively, numbers by value when natural, the exact text breaking ties.
+func compareText(a, b string, natural bool) int {
+	la, lb := strings.ToLower(a), strings.ToLower(b)
+	c := strings.Compare(la, lb)
+	if natural {
+		c = compareNatural(la, lb)
+	}
+	if c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+// compareNatural compares runs of digits by their value and everything else byte by byte.
+func compareNatural(a, b string) int {
+	for a != "" && b != "" {
+		da, db := digitPrefix(a), digitPrefix(b)
+		if da == "" || db == "" {
+			if a[0] != b[0] {
+				return strings.Compare(a[:1], b[:1])
 			}
-			return ai < bi
+			a, b = a[1:], b[1:]
+			continue
 		}
-	})
+		// Compare the values without leading zeros: longer is larger, then digit by digit
+		na, nb := strings.TrimLeft(da, "0"), strings.TrimLeft(db, "0")
+		if len(na) != len(nb) {
+			return len(na) - len(nb)
+		}
+		if c := strings.Compare(na, nb); c != 0 {
+			return c
+		}
+		if len(da) != len(db) {
+			return len(da) - len(db)
+		}
+		a, b = a[len(da):], b[len(db):]
+	}
+	return len(a) - len(b)
+}
+
+func digitPrefix(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i]
+}
+
+// sortEntries sorts by the keys of SortSpec, the default order when empty.
+func (p *PlaylistOutput) sortEntries() {
+	spec := p.SortSpec
+	if len(spec) == 0 {
+		spec = defaultSortSpec(p.GroupOrder)
+	}
+	p.sortEntriesBy(spec)
 }
 
 func (p *Playlist) filterScheduledEntries(withLocalTime, applyRange bool, expiredAfter, includeUntil time.Duration) {
@@ -80,18 +130,32 @@ func (p *Playlist) filterScheduledEntries(withLocalTime, applyRange bool, expire
 	p.Entries = out
 }
 
-func (p *Playlist) filterRemoveWithTitle(substrs []string) {
-	out := p.Entries[:0]
+// markFavorites flags the games involving any of the favorite teams and prefixes their titles with marker.
+func (p *Playlist) markFavorites(favorites map[string]bool, marker string) {
 	for _, e := range p.Entries {
-		titleLower := strings.ToLower(e.Info.Title)
-		exclude := false
-		for _, sub := range substrs {
-			if strings.Contains(titleLower, sub) {
-				exclude = true
-				break
-			}
+		teams := []string{e.Info.NBAHome(), e.Info.NBAAway()}
+		if m := e.Info.NBAMatch; m != nil {
+			teams = []string{m.Team1.Acronym, m.Team2.Acronym}
+		}
+		if !favorites[teams[0]] && !favorites[teams[1]] {
+			continue
 		}
-		if !exclude {
+		e.Info.Favorite = true
+		e.Info.Title = marker + e.Info.Title
+	}
+}
+
+// annotate makes the derived attributes of the entries part of the output, see --annotate.
+func (p *Playlist) annotate() {
+	for _, e := range p.Entries {
+		e.Info.derived = nil
+	}
+}
+
+func (p *Playlist) filterFavorites() {
+	out := p.Entries[:0]
+	for _, e := range p.Entries {
+		if e.Info.Favorite {
 			out = append(out, e)
 		}
 	}
@@ -126,13 +190,16 @@ func (p *Playlist) cleanseTitles(cleansers []Cleanser) {
 	}
 }
 
-func (p *Playlist) processNBAEntries(fallbackYear int) {
+// processNBAEntries returns the teams that were not an exact match, accepted or not, for diagnostics.
+func (p *Playlist) processNBAEntries(fallbackYear int) []TeamMatchDiagnostic {
 	var matchIdStartTimeMap = make(map[string]time.Time)
+	var diagnostics []TeamMatchDiagnostic
 	for n := range p.Entries {
 		titleGroups := parseTitle(p.Entries[n].Info.TitleCopy)
 		if titleGroups == nil {
 			continue
 		}
+		diagnostics = append(diagnostics, teamMatchDiagnostics(p.Entries[n].Info.TitleCopy, titleGroups)...)
 		match := parseNBAMatch(titleGroups, fallbackYear)
 		if match == nil {
 			continue
@@ -152,7 +219,7 @@ func (p *Playlist) processNBAEntries(fallbackYear int) {
 			match.Team2.TeamName,
 			match.Team2.Acronym,
 			streamType)
-		p.Entries[n].Info.SetNBAMatchId()
+		p.Entries[n].Info.SetNBAMatch(match)
 
 		matchId := p.Entries[n].Info.NBAMatchId()
 
@@ -168,9 +235,21 @@ func (p *Playlist) processNBAEntries(fallbackYear int) {
 		}
 	}
 
+	// Streams titled "Away @ Home" tell the home team of the "vs" and "x" streams of the same game
+	homes := make(map[string]*NBAMatch)
+	for _, e := range p.Entries {
+		if m := e.Info.NBAMatch; m != nil && m.HomeKnown() && homes[m.MatchId()] == nil {
+			homes[m.MatchId()] = m
+		}
+	}
+
 	// Finalize the titles with the latest start time + Home/Away Stream info
 	for n := range p.Entries {
 		matchId := p.Entries[n].Info.NBAMatchId()
+		if m := p.Entries[n].Info.NBAMatch; m != nil && !m.HomeKnown() && homes[matchId] != nil {
+			m.Home, m.Away = homes[matchId].Home, homes[matchId].Away
+			p.Entries[n].Info.SetNBAMatch(m)
+		}
 		if matchId != "" {
 			if tLocal, ok := matchIdStartTimeMap[matchId]; ok {
 				diffDays := getDateOnlyDiffDays(tLocal)
@@ -184,27 +263,52 @@ func (p *Playlist) processNBAEntries(fallbackYear int) {
 			}
 		}
 	}
+	return diagnostics
+}
+
+// Ways to split the output into several playlists, see --split-by
+const (
+	SplitByNone    = ""
+	SplitByGroup   = "group"
+	SplitByCountry = "country"
+	SplitByType    = "type"
+)
+
+func splitKey(splitBy string, e *PlaylistEntry) string {
+	switch splitBy {
+	case SplitByGroup:
+		return strings.ToUpper(e.Info.GroupTitle())
+	case SplitByCountry:
+		if country := strings.ToUpper(e.Info.GetAttr("tvg-country")); country != "" {
+			return country
+		}
+		return "UNKNOWN"
+	case SplitByType:
+		return strings.ToUpper(e.Info.ContentType())
+	}
+	return "ALL"
 }
 
-func (p *Playlist) generateOutput(splitByGroupTitle bool) map[string]PlaylistOutput {
+func (p *Playlist) generateOutput(splitBy string) map[string]PlaylistOutput {
 
-	if !splitByGroupTitle {
+	if splitBy == SplitByNone {
 		return map[string]PlaylistOutput{
 			"ALL": {
-				Entries: p.Entries,
+				Entries:    p.Entries,
+				GroupOrder: p.GroupOrder,
 			},
 		}
 	}
-	groupTitleMap := make(map[string]PlaylistOutput)
+	splitMap := make(map[string]PlaylistOutput)
 	for _, e := range p.Entries {
-		groupTitle := strings.ToUpper(e.Info.GroupTitle())
+		key := splitKey(splitBy, e)
 
-		po, ok := groupTitleMap[groupTitle]
+		po, ok := splitMap[key]
 		if !ok {
-			po = PlaylistOutput{}
+			po = PlaylistOutput{GroupOrder: p.GroupOrder}
 		}
 		po.Entries = append(po.Entries, e)
-		groupTitleMap[groupTitle] = po
+		splitMap[key] = po
 	}
-	return groupTitleMap
+	return splitMap
 }
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestEntry(title string, attrs map[string]string, start *time.Time) *PlaylistEntry {
+	e := &PlaylistEntry{URI: "http://example.com/" + title}
+	e.Info.Title = title
+	e.Info.TitleCopy = title
+	e.Info.StartTimeLocal = start
+	for k, v := range attrs {
+		e.Info.SetAttr(k, v)
+	}
+	return e
+}
+
+func TestFavoritesPinnedAndFiltered(t *testing.T) {
+	early := time.Date(2025, 12, 6, 19, 0, 0, 0, time.Local)
+	late := early.Add(3 * time.Hour)
+	p := Playlist{Entries: []*PlaylistEntry{
+		newTestEntry("Heat vs Kings", map[string]string{"nba-home": "MIA", "nba-away": "SAC"}, &early),
+		newTestEntry("Lakers vs Celtics", map[string]string{"nba-home": "LAL", "nba-away": "BOS"}, &late),
+		newTestEntry("Celtics x Knicks", map[string]string{"nba-home": "BOS", "nba-away": "NYK"}, &early),
+	}}
+	favorites, err := resolveFavoriteTeams([]string{"bos", "Lakers"})
+	if err != nil {
+		t.Fatalf("resolveFavoriteTeams: %v", err)
+	}
+	p.markFavorites(favorites, "* ")
+
+	out := PlaylistOutput{Entries: append([]*PlaylistEntry(nil), p.Entries...)}
+	out.sortEntries()
+	want := []string{"* Celtics x Knicks", "* Lakers vs Celtics", "Heat vs Kings"}
+	for i, e := range out.Entries {
+		if e.Info.Title != want[i] {
+			t.Errorf("sorted[%d] = %q, want %q", i, e.Info.Title, want[i])
+		}
+	}
+
+	p.filterFavorites()
+	if len(p.Entries) != 2 {
+		t.Errorf("filterFavorites kept %d entries, want 2", len(p.Entries))
+	}
+
+	if _, err := resolveFavoriteTeams([]string{"Seahawks"}); err == nil {
+		t.Error("expected error for unknown favorite team")
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const FormatPLS = "pls"
+
+// writePLS writes the entries as a PLS playlist (version 2), which only knows titles, URIs and lengths.
+func writePLS(outPath string, entries []*PlaylistEntry) error {
+	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	w.WriteString("[playlist]\n")
+	for i, e := range entries {
+		n := strconv.Itoa(i + 1)
+		length := e.Info.Duration
+		if length <= 0 {
+			// Live streams have no length
+			length = -1
+		}
+		w.WriteString("File" + n + "=" + e.URI + "\n")
+		w.WriteString("Title" + n + "=" + e.Info.Title + "\n")
+		w.WriteString("Length" + n + "=" + strconv.Itoa(length) + "\n")
+	}
+	w.WriteString("NumberOfEntries=" + strconv.Itoa(len(entries)) + "\n")
+	w.WriteString("Version=2\n")
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestWritePLS_Golden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.pls")
+	if err := writePLS(path, goldenEntries()); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, path, "golden.pls")
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Quality, codec and variant markers found in titles, normalized
+var (
+	qualityTags = map[string]string{
+		"4K": "4K", "UHD": "4K", "2160P": "4K",
+		"FHD": "FHD", "1080P": "FHD", "1080I": "FHD",
+		"HD": "HD", "720P": "HD",
+		"SD": "SD", "480P": "SD",
+	}
+	qualityRanks = map[string]int{"4K": 4, "FHD": 3, "HD": 2, "SD": 1}
+
+	codecTags = map[string]string{
+		"HEVC": "HEVC", "H265": "HEVC", "H.265": "HEVC", "X265": "HEVC",
+		"H264": "H264", "H.264": "H264", "AVC": "H264", "X264": "H264",
+	}
+	variantTags = map[string]string{
+		"VIP": "vip", "BACKUP": "backup", "ALT": "alt", "ALTERNATE": "alt", "RAW": "raw",
+	}
+
+	// Tags that are also title words ("WWE RAW", "Alt Nation", "AVC Sports"): tags only in brackets or after a
+	// separator, "[RAW]" or "ESPN | ALT"
+	ambiguousTags = map[string]bool{"RAW": true, "ALT": true, "ALTERNATE": true, "AVC": true}
+	tagSeparators = map[string]bool{"|": true, "-": true, "–": true, ":": true, "/": true}
+
+	// "UKFHD", "USAHD": country prefix glued to the quality
+	reGluedQuality = regexp.MustCompile(`^(?i)(UK|US|USA|CA|DE|NL|BR)(4K|UHD|FHD|HD|SD)$`)
+	// "50FPS", "60fps"
+	reFrameRate = regexp.MustCompile(`^(?i)(\d{2})FPS$`)
+)
+
+type StreamTags struct {
+	Quality  string
+	Codec    string
+	Variants []string
+	// Title without the tags
+	Stripped string
+}
+
+// extractStreamTags finds the quality ("4K", "FHD", "HD", "SD"), codec ("HEVC", "H264") and variants ("vip",
+// "backup", "alt", "raw", "50fps") of a title. The best quality wins when several are present.
+func extractStreamTags(title string) StreamTags {
+	var tags StreamTags
+	fields := strings.Fields(title)
+	kept := make([]string, 0, len(fields))
+	addVariant := func(variant string) {
+		for _, v := range tags.Variants {
+			if v == variant {
+				return
+			}
+		}
+		tags.Variants = append(tags.Variants, variant)
+	}
+	setQuality := func(quality string) {
+		if qualityRanks[quality] > qualityRanks[tags.Quality] {
+			tags.Quality = quality
+		}
+	}
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		core := strings.ToUpper(strings.Trim(field, "[]()"))
+		if ambiguousTags[core] && core == strings.ToUpper(field) && (i == 0 || !tagSeparators[fields[i-1]]) {
+			kept = append(kept, field)
+			continue
+		}
+		if quality, ok := qualityTags[core]; ok {
+			setQuality(quality)
+			continue
+		}
+		if codec, ok := codecTags[core]; ok {
+			tags.Codec = codec
+			continue
+		}
+		if variant, ok := variantTags[core]; ok {
+			addVariant(variant)
+			continue
+		}
+		if m := reFrameRate.FindStringSubmatch(core); m != nil {
+			addVariant(m[1] + "fps")
+			continue
+		}
+		if i+1 < len(fields) && strings.EqualFold(fields[i+1], "FPS") && reFrameRate.MatchString(core+"FPS") {
+			addVariant(core + "fps")
+			i++
+			continue
+		}
+		if m := reGluedQuality.FindStringSubmatch(core); m != nil {
+			setQuality(qualityTags[strings.ToUpper(m[2])])
+			kept = append(kept, field[:len(m[1])])
+			continue
+		}
+		kept = append(kept, field)
+	}
+	tags.Stripped = strings.Trim(strings.Join(kept, " "), " |-:")
+	return tags
+}
+
+func qualityRank(quality string) int {
+	return qualityRanks[quality]
+}
+
+// QualityRank ranks the quality attribute, higher is better, 0 when unknown.
+func (e *ExtInf) QualityRank() int {
+	return qualityRank(e.GetAttr("quality"))
+}
+
+// tagStreams stores the tags of every entry as derived quality, codec and variant attributes, and strips them
+// from the displayed title when asked.
+func (p *Playlist) tagStreams(stripTitle bool) {
+	for _, e := range p.Entries {
+		tags := extractStreamTags(e.Info.TitleCopy)
+		if tags.Quality != "" {
+			e.Info.SetDerivedAttr("quality", tags.Quality)
+		}
+		if tags.Codec != "" {
+			e.Info.SetDerivedAttr("codec", tags.Codec)
+		}
+		if len(tags.Variants) > 0 {
+			e.Info.SetDerivedAttr("variant", strings.Join(tags.Variants, ","))
+		}
+		if stripTitle && tags.Stripped != "" {
+			e.Info.Title = tags.Stripped
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractStreamTags(t *testing.T) {
+	tests := []struct {
+		title    string
+		quality  string
+		codec    string
+		variants []string
+		stripped string
+	}{
+		{"UK FHD  Sky Sports Main Event FHD", "FHD", "", nil, "UK Sky Sports Main Event"},
+		{"UKFHD | Sky Sports F1 50FPS FHD", "FHD", "", []string{"50fps"}, "UK | Sky Sports F1"},
+		{"UKFHD | Sky Sports Main Event 50 FPS", "FHD", "", []string{"50fps"}, "UK | Sky Sports Main Event"},
+		{"USA  HGTV HD", "HD", "", nil, "USA HGTV"},
+		{"24/7 The Sopranos S06 [VIP]", "", "", []string{"vip"}, "24/7 The Sopranos S06"},
+		{"ESPN 4K HEVC Backup", "4K", "HEVC", []string{"backup"}, "ESPN"},
+		{"Sky Sports Golf HD (Alt)", "HD", "", []string{"alt"}, "Sky Sports Golf"},
+		{"WWE RAW", "", "", nil, "WWE RAW"},
+		{"WWE Raw [RAW] HD", "HD", "", []string{"raw"}, "WWE Raw"},
+		{"ESPN | ALT", "", "", []string{"alt"}, "ESPN"},
+		{"AVC Sports H264", "", "H264", nil, "AVC Sports"},
+		{"NBA 01: Pelicans vs Nets (Home) (12.06 5:00PM ET)", "", "", nil, "NBA 01: Pelicans vs Nets (Home) (12.06 5:00PM ET)"},
+	}
+	for _, tt := range tests {
+		tags := extractStreamTags(tt.title)
+		if tags.Quality != tt.quality || tags.Codec != tt.codec || !reflect.DeepEqual(tags.Variants, tt.variants) {
+			t.Errorf("extractStreamTags(%q) = %q/%q/%v, want %q/%q/%v", tt.title, tags.Quality, tags.Codec, tags.Variants, tt.quality, tt.codec, tt.variants)
+		}
+		if tags.Stripped != tt.stripped {
+			t.Errorf("extractStreamTags(%q).Stripped = %q, want %q", tt.title, tags.Stripped, tt.stripped)
+		}
+	}
+}
+
+func TestSortEntries_PrefersQuality(t *testing.T) {
+	p := Playlist{Entries: []*PlaylistEntry{
+		newTestEntry("UKHD Sky Sports F1 HD", nil, nil),
+		newTestEntry("Sky Sports Cricket SD", nil, nil),
+		newTestEntry("UK FHD  Sky Sports F1 FHD", nil, nil),
+		newTestEntry("Sky Sports F1 4K", nil, nil),
+	}}
+	p.tagStreams(false)
+	out := PlaylistOutput{Entries: p.Entries}
+	out.sortEntries()
+	want := []string{"Sky Sports Cricket SD", "Sky Sports F1 4K", "UK FHD  Sky Sports F1 FHD", "UKHD Sky Sports F1 HD"}
+	for i, e := range out.Entries {
+		if e.Info.Title != want[i] {
+			t.Errorf("sorted[%d] = %q, want %q", i, e.Info.Title, want[i])
+		}
+	}
+}
+
+func TestTagStreams_WrittenWithAnnotate(t *testing.T) {
+	p := Playlist{Entries: []*PlaylistEntry{
+		newTestEntry("ESPN FHD", map[string]string{"group-title": "SPORTS"}, nil),
+		newTestEntry("Sky Sports HD", map[string]string{"quality": "SD"}, nil),
+	}}
+	p.tagStreams(false)
+	if q := p.Entries[0].Info.GetAttr("quality"); q != "FHD" {
+		t.Errorf("quality = %q, want FHD", q)
+	}
+	if line := writeNewEntry(p.Entries[0]); strings.Contains(line, "quality=") {
+		t.Errorf("derived quality written without --annotate: %q", line)
+	}
+	// The provider's attribute is updated and still written
+	if line := writeNewEntry(p.Entries[1]); !strings.Contains(line, `quality="HD"`) {
+		t.Errorf("provider quality not written: %q", line)
+	}
+	p.annotate()
+	if line := writeNewEntry(p.Entries[0]); !strings.Contains(line, `quality="FHD"`) {
+		t.Errorf("derived quality not written with --annotate: %q", line)
+	}
+}
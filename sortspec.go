@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SortKey is one key of a --sort-by specification like "-start" or "title:natural".
+type SortKey struct {
+	Name       string
+	Descending bool
+	Natural    bool
+}
+
+type SortSpec []SortKey
+
+// sortKeyFunc compares two entries on one key; ok is false for an entry without the key.
+type sortKeyFunc func(p *PlaylistOutput, a, b *PlaylistEntry, natural bool) (c int, aOK, bOK bool)
+
+// Named keys of --sort-by, the comparisons of the default order
+var sortKeys = map[string]sortKeyFunc{
+	// Configured group order (see group_map), then group name
+	"group": func(p *PlaylistOutput, a, b *PlaylistEntry, natural bool) (int, bool, bool) {
+		ag, bg := a.Info.GroupTitle(), b.Info.GroupTitle()
+		if p.GroupOrder != nil {
+			if c := groupRank(p.GroupOrder, ag) - groupRank(p.GroupOrder, bg); c != 0 {
+				return c, ag != "", bg != ""
+			}
+		}
+		return compareText(ag, bg, natural), ag != "", bg != ""
+	},
+	// Favorite games first
+	"favorite": func(_ *PlaylistOutput, a, b *PlaylistEntry, _ bool) (int, bool, bool) {
+		return 0, a.Info.Favorite, b.Info.Favorite
+	},
+	"start": func(_ *PlaylistOutput, a, b *PlaylistEntry, _ bool) (int, bool, bool) {
+		at, bt := a.Info.StartTimeLocal, b.Info.StartTimeLocal
+		if at == nil || bt == nil {
+			return 0, at != nil, bt != nil
+		}
+		return at.Compare(*bt), true, true
+	},
+	// Match ID, the best streams of a match first
+	"match": func(_ *PlaylistOutput, a, b *PlaylistEntry, _ bool) (int, bool, bool) {
+		am, bm := a.Info.NBAMatchId(), b.Info.NBAMatchId()
+		if c := strings.Compare(am, bm); c != 0 || am == "" {
+			return c, am != "", bm != ""
+		}
+		return b.Info.QualityRank() - a.Info.QualityRank(), true, true
+	},
+	// Higher quality first
+	"quality": func(_ *PlaylistOutput, a, b *PlaylistEntry, _ bool) (int, bool, bool) {
+		ar, br := a.Info.QualityRank(), b.Info.QualityRank()
+		return br - ar, ar > 0, br > 0
+	},
+	"title": func(_ *PlaylistOutput, a, b *PlaylistEntry, natural bool) (int, bool, bool) {
+		return compareText(a.Info.Title, b.Info.Title, natural), a.Info.Title != "", b.Info.Title != ""
+	},
+	// Title after the colon ("NBA 05: Kings vs Heat"), the whole title without one, of scheduled events: entries
+	// without a start time are left to the next keys
+	"event": func(_ *PlaylistOutput, a, b *PlaylistEntry, natural bool) (int, bool, bool) {
+		if a.Info.StartTimeLocal == nil || b.Info.StartTimeLocal == nil {
+			return 0, false, false
+		}
+		ae, be := eventTitle(a.Info.Title), eventTitle(b.Info.Title)
+		return compareText(ae, be, natural), ae != "", be != ""
+	},
+	// Channel name without country prefix and quality tags
+	"channel": func(_ *PlaylistOutput, a, b *PlaylistEntry, natural bool) (int, bool, bool) {
+		ac, bc := normalizeChannelTitle(a.Info.TitleCopy), normalizeChannelTitle(b.Info.TitleCopy)
+		return compareText(ac, bc, natural), ac != "", bc != ""
+	},
+	// Series name, season and episode
+	"series": func(_ *PlaylistOutput, a, b *PlaylistEntry, natural bool) (int, bool, bool) {
+		as, bs := a.Info.GetAttr("series-name"), b.Info.GetAttr("series-name")
+		if c := compareText(as, bs, natural); c != 0 && !strings.EqualFold(as, bs) {
+			return c, as != "", bs != ""
+		}
+		return compareSeasons(a, b), as != "", bs != ""
+	},
+	// Start of the programme annotated from the guide (see --epg)
+	"programme": func(_ *PlaylistOutput, a, b *PlaylistEntry, _ bool) (int, bool, bool) {
+		at, aOK := a.Info.EPGStart()
+		bt, bOK := b.Info.EPGStart()
+		return at.Compare(bt), aOK, bOK
+	},
+	// Season and episode of the same series, other entries are left to the next keys
+	"episode": func(_ *PlaylistOutput, a, b *PlaylistEntry, _ bool) (int, bool, bool) {
+		as := a.Info.GetAttr("series-name")
+		if as == "" || !strings.EqualFold(as, b.Info.GetAttr("series-name")) {
+			return 0, false, false
+		}
+		return compareSeasons(a, b), true, true
+	},
+	"chno": func(_ *PlaylistOutput, a, b *PlaylistEntry, _ bool) (int, bool, bool) {
+		ac, aOK := a.Info.TvgChno()
+		bc, bOK := b.Info.TvgChno()
+		return ac - bc, aOK, bOK
+	},
+}
+
+// defaultSortSpec is the order of --sort: favorites, start time, streams of a match, events starting together by
+// name, episodes of a series, then channel, quality and title. Groups only come first when group_map orders them.
+func defaultSortSpec(groupOrder map[string]int) SortSpec {
+	spec := SortSpec{{Name: "favorite"}, {Name: "start"}, {Name: "match"}, {Name: "event"}, {Name: "episode"}, {Name: "channel"}, {Name: "quality"}, {Name: "title"}}
+	if groupOrder != nil {
+		spec = append(SortSpec{{Name: "group"}}, spec...)
+	}
+	return spec
+}
+
+// eventTitle is the title after the colon when present ("NBA 05: Kings vs Heat").
+func eventTitle(title string) string {
+	if _, event, ok := strings.Cut(title, ":"); ok {
+		return event
+	}
+	return title
+}
+
+func sortKeyNames() []string {
+	names := make([]string, 0, len(sortKeys))
+	for name := range sortKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseSortSpec reads a comma-separated list of keys, "-" in front for descending order and ":natural" after
+// to compare numbers in text by value.
+func parseSortSpec(spec string) (SortSpec, error) {
+	var keys SortSpec
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		var key SortKey
+		if strings.HasPrefix(field, "-") {
+			key.Descending = true
+			field = field[1:]
+		} else {
+			field = strings.TrimPrefix(field, "+")
+		}
+		name, option, _ := strings.Cut(field, ":")
+		key.Name = strings.ToLower(name)
+		if _, ok := sortKeys[key.Name]; !ok {
+			return nil, fmt.Errorf("unknown sort key %q, expected one of %s", name, strings.Join(sortKeyNames(), ", "))
+		}
+		switch strings.ToLower(option) {
+		case "":
+		case "natural":
+			key.Natural = true
+		default:
+			return nil, fmt.Errorf("unknown option %q of sort key %q", option, name)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("empty sort specification")
+	}
+	return keys, nil
+}
+
+// sortEntriesBy sorts stably on the keys in turn. Entries without a key come after those with it, in both
+// directions.
+func (p *PlaylistOutput) sortEntriesBy(spec SortSpec) {
+	sort.SliceStable(p.Entries, func(i, j int) bool {
+		a, b := p.Entries[i], p.Entries[j]
+		for _, key := range spec {
+			c, aOK, bOK := sortKeys[key.Name](p, a, b, key.Natural || p.NaturalSort)
+			switch {
+			case !aOK && !bOK:
+				continue
+			case aOK != bOK:
+				return aOK
+			}
+			if key.Descending {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseSortSpec(t *testing.T) {
+	spec, err := parseSortSpec("start, group,match,quality,title:natural,-chno")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SortSpec{{Name: "start"}, {Name: "group"}, {Name: "match"}, {Name: "quality"}, {Name: "title", Natural: true}, {Name: "chno", Descending: true}}
+	if len(spec) != len(want) {
+		t.Fatalf("got %v, want %v", spec, want)
+	}
+	for i := range want {
+		if spec[i] != want[i] {
+			t.Errorf("key #%d = %+v, want %+v", i, spec[i], want[i])
+		}
+	}
+	for _, bad := range []string{"", "colour", "title:fancy"} {
+		if _, err := parseSortSpec(bad); err == nil {
+			t.Errorf("parseSortSpec(%q): expected error", bad)
+		}
+	}
+}
+
+func TestSortEntriesBy(t *testing.T) {
+	early := time.Date(2025, 12, 6, 19, 0, 0, 0, time.Local)
+	late := early.Add(2 * time.Hour)
+	entries := []*PlaylistEntry{
+		newTestEntry("NBA 10", map[string]string{"tvg-chno": "3", "quality": "HD"}, &late),
+		newTestEntry("NBA 2", map[string]string{"tvg-chno": "5", "quality": "FHD"}, &early),
+		newTestEntry("ESPN", map[string]string{"quality": "SD"}, nil),
+		newTestEntry("NBA 1", map[string]string{"tvg-chno": "4"}, &early),
+	}
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		// Missing start goes last in both directions
+		{"start,title:natural", []string{"NBA 1", "NBA 2", "NBA 10", "ESPN"}},
+		{"-start,title:natural", []string{"NBA 10", "NBA 1", "NBA 2", "ESPN"}},
+		{"-chno", []string{"NBA 2", "NBA 1", "NBA 10", "ESPN"}},
+		{"quality", []string{"NBA 2", "NBA 10", "ESPN", "NBA 1"}},
+		{"title", []string{"ESPN", "NBA 1", "NBA 10", "NBA 2"}},
+	}
+	for _, tt := range tests {
+		spec, err := parseSortSpec(tt.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := PlaylistOutput{Entries: append([]*PlaylistEntry(nil), entries...), SortSpec: spec}
+		out.sortEntries()
+		for i, e := range out.Entries {
+			if e.Info.Title != tt.want[i] {
+				t.Errorf("%s: #%d = %q, want %q", tt.spec, i, e.Info.Title, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestSortEntries_DefaultIsStable(t *testing.T) {
+	var out PlaylistOutput
+	for i := 0; i < 20; i++ {
+		e := newTestEntry("ESPN", nil, nil)
+		e.URI = fmt.Sprintf("http://example.com/%02d", i)
+		out.Entries = append(out.Entries, e)
+	}
+	out.Entries = append(out.Entries, newTestEntry("CNN", nil, nil))
+	out.sortEntries()
+	if out.Entries[0].Info.Title != "CNN" {
+		t.Errorf("first = %q, want CNN", out.Entries[0].Info.Title)
+	}
+	for i, e := range out.Entries[1:] {
+		if want := fmt.Sprintf("http://example.com/%02d", i); e.URI != want {
+			t.Fatalf("#%d = %s, want %s: equal entries reordered", i+1, e.URI, want)
+		}
+	}
+}
+
+func TestSortEntries_DefaultEventOrder(t *testing.T) {
+	// Without --nba there is no match id: games starting together are ordered by the text after the colon
+	start := time.Date(2025, 12, 6, 20, 0, 0, 0, time.UTC)
+	out := PlaylistOutput{Entries: []*PlaylistEntry{
+		newTestEntry("NBA 02: Kings vs Heat", nil, &start),
+		newTestEntry("NBA 05: Celtics vs Lakers", nil, &start),
+	}}
+	out.sortEntries()
+	if out.Entries[0].Info.Title != "NBA 05: Celtics vs Lakers" {
+		t.Errorf("first = %q, want the Celtics game", out.Entries[0].Info.Title)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Default team catalogs, one file per league. They can be overridden at runtime with --teams.
+//
+//go:embed teams/*.json
+var embeddedTeamCatalogs embed.FS
+
+type TeamCatalog struct {
+	League string         `json:"league" yaml:"league"`
+	Teams  []NBAFranchise `json:"teams" yaml:"teams"`
+}
+
+var (
+	// Loaded catalogs by upper-cased league name
+	teamCatalogs = mustLoadEmbeddedTeamCatalogs()
+
+	reHexColor = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+)
+
+func mustLoadEmbeddedTeamCatalogs() map[string]*TeamCatalog {
+	catalogs := make(map[string]*TeamCatalog)
+	files, err := embeddedTeamCatalogs.ReadDir("teams")
+	if err != nil {
+		panic(err)
+	}
+	for _, file := range files {
+		name := path.Join("teams", file.Name())
+		data, err := embeddedTeamCatalogs.ReadFile(name)
+		if err != nil {
+			panic(err)
+		}
+		catalog, err := parseTeamCatalog(name, data)
+		if err != nil {
+			panic(err)
+		}
+		catalogs[strings.ToUpper(catalog.League)] = catalog
+	}
+	return catalogs
+}
+
+// loadTeamCatalogFile reads a JSON or YAML team catalog and replaces the catalog of the same league.
+func loadTeamCatalogFile(filePath string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	catalog, err := parseTeamCatalog(filePath, data)
+	if err != nil {
+		return err
+	}
+	league := strings.ToUpper(catalog.League)
+	teamCatalogs[league] = catalog
+	if league == "NBA" {
+		NBAFranchises = catalog.Teams
+	}
+	return nil
+}
+
+func parseTeamCatalog(name string, data []byte) (*TeamCatalog, error) {
+	var catalog TeamCatalog
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &catalog); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	default:
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if err := catalog.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return &catalog, nil
+}
+
+func (c *TeamCatalog) validate() error {
+	if strings.TrimSpace(c.League) == "" {
+		return fmt.Errorf("missing league")
+	}
+	if len(c.Teams) == 0 {
+		return fmt.Errorf("league %s has no teams", c.League)
+	}
+	// Acronyms and alternates share the same namespace, since both are matched as "(ACR)" in titles
+	acronymOwners := make(map[string]string)
+	for i, team := range c.Teams {
+		if team.Name == "" {
+			return fmt.Errorf("team #%d: missing name", i+1)
+		}
+		if team.TeamName == "" {
+			return fmt.Errorf("team %q: missing team_name", team.Name)
+		}
+		if team.Acronym == "" {
+			return fmt.Errorf("team %q: missing acronym", team.Name)
+		}
+		for _, acronym := range append([]string{team.Acronym}, team.Alternates...) {
+			key := strings.ToUpper(acronym)
+			if owner, ok := acronymOwners[key]; ok {
+				if owner == team.Name {
+					return fmt.Errorf("team %q: acronym %q listed twice", team.Name, acronym)
+				}
+				return fmt.Errorf("duplicate acronym %q used by %q and %q", acronym, owner, team.Name)
+			}
+			acronymOwners[key] = team.Name
+		}
+		for _, color := range team.Colors {
+			if !reHexColor.MatchString(color) {
+				return fmt.Errorf("team %q: invalid color %q, expected #RRGGBB", team.Name, color)
+			}
+		}
+	}
+	return nil
+}
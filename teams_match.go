@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Confidence scores for the different ways a team can be recognized in a title
+const (
+	teamConfidenceExact    = 1.0  // full name, team name or "(ACR)" (case-insensitive)
+	teamConfidenceNickname = 0.95 // nickname alias like "Sixers" or "Cavs"
+	teamConfidenceAcronym  = 0.9  // bare upper-case acronym like "LAL"
+	teamConfidenceFuzzy    = 0.95 // multiplied by the edit distance similarity
+	teamFuzzyMinLength     = 4    // shorter aliases are too ambiguous for edit distance
+)
+
+// Teams matched below this confidence are rejected (see --team-threshold)
+var minTeamConfidence = 0.8
+
+// TeamMatchDiagnostic describes a team name that was not an exact match.
+type TeamMatchDiagnostic struct {
+	Title      string
+	Text       string
+	Franchise  *NBAFranchise
+	Confidence float64
+	Accepted   bool
+}
+
+var accentFolds = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae",
+	'ç': "c", 'è': "e", 'é': "e", 'ê': "e", 'ë': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ñ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'œ': "oe",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ý': "y", 'ÿ': "y", 'ß': "ss",
+}
+
+// normalizeTeamText case-folds, strips accents and reduces separators to single spaces.
+func normalizeTeamText(s string) string {
+	var b strings.Builder
+	space := true
+	for _, r := range strings.ToLower(s) {
+		if fold, ok := accentFolds[r]; ok {
+			b.WriteString(fold)
+			space = false
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			space = false
+			continue
+		}
+		if !space {
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// teamMatchDiagnostics reports both teams of a parsed title when they were not an exact match.
+func teamMatchDiagnostics(title string, titleGroups *NBATitleGroups) []TeamMatchDiagnostic {
+	var diagnostics []TeamMatchDiagnostic
+	for _, text := range []string{titleGroups.Team1, titleGroups.Team2} {
+		franchise, confidence := matchNBAFranchise(text)
+		if confidence >= teamConfidenceExact {
+			continue
+		}
+		diagnostics = append(diagnostics, TeamMatchDiagnostic{
+			Title:      title,
+			Text:       text,
+			Franchise:  franchise,
+			Confidence: confidence,
+			Accepted:   confidence >= minTeamConfidence,
+		})
+	}
+	return diagnostics
+}
+
+func (d TeamMatchDiagnostic) String() string {
+	status := "rejected"
+	if d.Accepted {
+		status = "accepted"
+	}
+	guess := "no team"
+	if d.Franchise != nil {
+		guess = d.Franchise.Name
+	}
+	return fmt.Sprintf("%.2f %s %q -> %s (%s)", d.Confidence, status, d.Text, guess, d.Title)
+}
+
+// matchNBAFranchise returns the franchise that best matches text along with its confidence (0 when none).
+func matchNBAFranchise(text string) (*NBAFranchise, float64) {
+	normalized := normalizeTeamText(text)
+	padded := " " + normalized + " "
+	tokens := strings.Fields(normalized)
+	upperTokens := strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) })
+
+	var best *NBAFranchise
+	bestScore := 0.0
+	for i := range NBAFranchises {
+		franchise := &NBAFranchises[i]
+		score := scoreNBAFranchise(franchise, text, padded, tokens, upperTokens)
+		if score > bestScore {
+			best, bestScore = franchise, score
+		}
+	}
+	return best, bestScore
+}
+
+func scoreNBAFranchise(franchise *NBAFranchise, text, padded string, tokens, upperTokens []string) float64 {
+	if franchise.hasAcronymInText(text) {
+		return teamConfidenceExact
+	}
+	names := []string{franchise.Name, franchise.TeamName}
+	for _, name := range names {
+		if strings.Contains(padded, " "+normalizeTeamText(name)+" ") {
+			return teamConfidenceExact
+		}
+	}
+	for _, nickname := range franchise.Nicknames {
+		if strings.Contains(padded, " "+normalizeTeamText(nickname)+" ") {
+			return teamConfidenceNickname
+		}
+	}
+	for _, token := range upperTokens {
+		if token == franchise.Acronym {
+			return teamConfidenceAcronym
+		}
+		for _, alt := range franchise.Alternates {
+			if token == alt {
+				return teamConfidenceAcronym
+			}
+		}
+	}
+
+	best := 0.0
+	for _, alias := range append(names, franchise.Nicknames...) {
+		alias = normalizeTeamText(alias)
+		if len(alias) < teamFuzzyMinLength {
+			continue
+		}
+		if similarity := bestWindowSimilarity(alias, tokens); similarity*teamConfidenceFuzzy > best {
+			best = similarity * teamConfidenceFuzzy
+		}
+	}
+	return best
+}
+
+// bestWindowSimilarity compares alias against every run of the same number of tokens in text.
+func bestWindowSimilarity(alias string, tokens []string) float64 {
+	width := len(strings.Fields(alias))
+	best := 0.0
+	for i := 0; i+width <= len(tokens); i++ {
+		window := strings.Join(tokens[i:i+width], " ")
+		if similarity := editSimilarity(alias, window); similarity > best {
+			best = similarity
+		}
+	}
+	return best
+}
+
+// editSimilarity is 1 minus the Levenshtein distance relative to the longer string.
+func editSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,61 @@
+package main
+
+import "testing"
+
+func TestMatchNBAFranchise(t *testing.T) {
+	tests := []struct {
+		text    string
+		acronym string
+		minConf float64
+		maxConf float64
+	}{
+		{"Minnesota Timberwolves", "MIN", 1, 1},
+		{"Spurs (SAS)", "SAS", 1, 1},
+		{"lakers", "LAL", 1, 1},
+		{"Charlotte Hornets", "CHA", 1, 1},
+		{"Miami Heat | Home Stream", "MIA", 1, 1},
+		{"Sixers", "PHI", teamConfidenceNickname, teamConfidenceNickname},
+		{"Cavs", "CLE", teamConfidenceNickname, teamConfidenceNickname},
+		{"Blazers", "POR", teamConfidenceNickname, teamConfidenceNickname},
+		{"GSW", "GSW", teamConfidenceAcronym, teamConfidenceAcronym},
+		{"Timberwoves", "MIN", 0.8, 0.95},
+		{"Montréal Canadiens", "", 0, 0.8},
+	}
+	for _, tt := range tests {
+		franchise, confidence := matchNBAFranchise(tt.text)
+		if confidence < tt.minConf || confidence > tt.maxConf {
+			t.Errorf("matchNBAFranchise(%q) confidence = %.2f, want in [%.2f, %.2f]", tt.text, confidence, tt.minConf, tt.maxConf)
+		}
+		if tt.acronym == "" {
+			continue
+		}
+		if franchise == nil || franchise.Acronym != tt.acronym {
+			t.Errorf("matchNBAFranchise(%q) = %v, want %s", tt.text, franchise, tt.acronym)
+		}
+	}
+}
+
+func TestNormalizeTeamText(t *testing.T) {
+	if got := normalizeTeamText("  Pélicans |  NEW-Orleans "); got != "pelicans new orleans" {
+		t.Errorf("normalizeTeamText = %q, want %q", got, "pelicans new orleans")
+	}
+}
+
+func TestTeamMatchDiagnostics(t *testing.T) {
+	groups := parseTitle("NBA 06: Timberwoves (MN) x Clipers | Sat 6th Dec 8:00PM ET")
+	if groups == nil {
+		t.Fatal("parseTitle returned nil")
+	}
+	diagnostics := teamMatchDiagnostics("title", groups)
+	if len(diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %d: %v", len(diagnostics), diagnostics)
+	}
+	for _, d := range diagnostics {
+		if !d.Accepted {
+			t.Errorf("expected %q to be accepted: %s", d.Text, d)
+		}
+	}
+	if parseNBAMatch(groups, 2025) == nil {
+		t.Error("parseNBAMatch rejected fuzzy team names above the threshold")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedNBACatalog(t *testing.T) {
+	if len(NBAFranchises) != 30 {
+		t.Fatalf("embedded NBA catalog has %d teams, want 30", len(NBAFranchises))
+	}
+	gsw := parseNBAFranchise("Golden State Warriors")
+	if gsw == nil || gsw.City != "Golden State" {
+		t.Errorf("Golden State Warriors = %#v, want City %q", gsw, "Golden State")
+	}
+	if nyk := parseNBAFranchise("Knicks (NY)"); nyk == nil || nyk.Acronym != "NYK" {
+		t.Errorf("alternate acronym (NY) resolved to %#v, want NYK", nyk)
+	}
+}
+
+func TestParseTeamCatalog_YAML(t *testing.T) {
+	data := `
+league: WNBA
+teams:
+  - name: Las Vegas Aces
+    city: Las Vegas
+    team_name: Aces
+    acronym: LVA
+    alternates: [LV]
+    colors: ["#000000", "#BA0C2F"]
+`
+	catalog, err := parseTeamCatalog("wnba.yaml", []byte(data))
+	if err != nil {
+		t.Fatalf("parseTeamCatalog: %v", err)
+	}
+	if catalog.League != "WNBA" || len(catalog.Teams) != 1 || catalog.Teams[0].Alternates[0] != "LV" {
+		t.Errorf("unexpected catalog %#v", catalog)
+	}
+}
+
+func TestParseTeamCatalog_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "duplicate acronym",
+			data: `{"league":"NBA","teams":[
+				{"name":"Los Angeles Clippers","team_name":"Clippers","acronym":"LAC"},
+				{"name":"Los Angeles Lakers","team_name":"Lakers","acronym":"LAC"}]}`,
+			want: `duplicate acronym "LAC" used by "Los Angeles Clippers" and "Los Angeles Lakers"`,
+		},
+		{
+			name: "alternate clashes with acronym",
+			data: `{"league":"NBA","teams":[
+				{"name":"New York Knicks","team_name":"Knicks","acronym":"NYK"},
+				{"name":"Brooklyn Nets","team_name":"Nets","acronym":"BKN","alternates":["nyk"]}]}`,
+			want: `duplicate acronym "nyk"`,
+		},
+		{
+			name: "missing acronym",
+			data: `{"league":"NBA","teams":[{"name":"Utah Jazz","team_name":"Jazz"}]}`,
+			want: `team "Utah Jazz": missing acronym`,
+		},
+		{
+			name: "bad color",
+			data: `{"league":"NBA","teams":[{"name":"Utah Jazz","team_name":"Jazz","acronym":"UTA","colors":["navy"]}]}`,
+			want: `invalid color "navy"`,
+		},
+		{
+			name: "missing league",
+			data: `{"teams":[{"name":"Utah Jazz","team_name":"Jazz","acronym":"UTA"}]}`,
+			want: "missing league",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTeamCatalog("teams.json", []byte(tt.data))
+			if err == nil {
+				t.Fatalf("expected error containing %q", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -210,6 +210,8 @@ func parseTimesFromTitleV2(title string) *time.Time {
 		"02 Jan 3:04pm ET",
 		"02 Jan 3:04pm",
 		"2006 01 02 03:04:05",
+		"01/02/2006 3:04 PM ET",
+		"01/02/2006 3:04 PM",
 	}
 	for _, format := range customFormats {
 		t, err := time.ParseInLocation(format, title, loc)
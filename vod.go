@@ -0,0 +1,163 @@
+package main
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Content types set as the content-type attribute, see --split-by type
+const (
+	ContentLive   = "live"
+	Content247    = "24/7"
+	ContentMovie  = "movie"
+	ContentSeries = "series"
+)
+
+var (
+	// "24/7 The Sopranos", "24-7 Friends"
+	re247 = regexp.MustCompile(`(?i)^\s*24\s*[/-]\s*7\b[\s:|-]*`)
+	// "S06E03", "S6 E3", "6x03"
+	reEpisode = regexp.MustCompile(`(?i)\b(?:S(\d{1,2})\s*E(\d{1,3})|(\d{1,2})x(\d{2,3}))\b`)
+	// "S06", "Season 6"
+	reSeason = regexp.MustCompile(`(?i)\b(?:S(\d{1,2})|Season\s+(\d{1,2}))\b`)
+	// "Heat (1995)", "Heat 1995"
+	reMovieYear = regexp.MustCompile(`[\s(\[]((?:19|20)\d{2})[)\]]?\s*$`)
+	// "VOD", "US Movies", "Films": groups where a year in the title means a movie, not a live channel like "Euro 2024"
+	reVODGroup = regexp.MustCompile(`(?i)\b(?:vod|movies?|films?)\b`)
+
+	vodExtensions = map[string]bool{".mp4": true, ".mkv": true, ".avi": true, ".m4v": true, ".mov": true}
+)
+
+// VODInfo is what classifyContent found in an entry.
+type VODInfo struct {
+	Type       string
+	SeriesName string
+	Season     int
+	Episode    int
+	MovieTitle string
+	MovieYear  int
+}
+
+// classifyContent tells live channels, 24/7 loops, movies and series episodes apart from the title, group and
+// URI ("/movie/" and "/series/" paths, video file extensions).
+func classifyContent(e *PlaylistEntry) VODInfo {
+	var info VODInfo
+	title := strings.TrimSpace(e.Info.TitleCopy)
+	loop := false
+	if m := re247.FindString(title); m != "" {
+		loop = true
+		title = title[len(m):]
+	} else if strings.Contains(e.Info.GroupTitle(), "24/7") {
+		loop = true
+	}
+	// Quality and variant tags are not part of the names
+	if stripped := extractStreamTags(title).Stripped; stripped != "" {
+		title = stripped
+	}
+	title = strings.TrimSpace(strings.Trim(title, "[]() "))
+
+	uriKind, vodFile := "", false
+	if u, err := url.Parse(e.URI); err == nil {
+		lower := strings.ToLower(u.Path)
+		switch {
+		case strings.Contains(lower, "/movie/"):
+			uriKind = ContentMovie
+		case strings.Contains(lower, "/series/"):
+			uriKind = ContentSeries
+		}
+		vodFile = vodExtensions[path.Ext(lower)]
+	}
+
+	if loc := reEpisode.FindStringSubmatchIndex(title); loc != nil {
+		m := reEpisode.FindStringSubmatch(title)
+		info.SeriesName = cleanVODName(title[:loc[0]])
+		info.Season, info.Episode = atoiFirst(m[1], m[3]), atoiFirst(m[2], m[4])
+	} else if loc := reSeason.FindStringSubmatchIndex(title); loc != nil {
+		m := reSeason.FindStringSubmatch(title)
+		info.SeriesName = cleanVODName(title[:loc[0]])
+		info.Season = atoiFirst(m[1], m[2])
+	}
+	switch {
+	case loop:
+		info.Type = Content247
+	case info.SeriesName != "" || uriKind == ContentSeries:
+		info.Type = ContentSeries
+	case uriKind == ContentMovie || vodFile || (reMovieYear.MatchString(title) && reVODGroup.MatchString(e.Info.GroupTitle())):
+		info.Type = ContentMovie
+	default:
+		info.Type = ContentLive
+	}
+	if info.Type == ContentSeries && info.SeriesName == "" {
+		info.SeriesName = cleanVODName(title)
+	}
+	if info.Type == ContentMovie {
+		info.MovieTitle = cleanVODName(title)
+		if loc := reMovieYear.FindStringSubmatchIndex(title); loc != nil {
+			info.MovieYear, _ = strconv.Atoi(title[loc[2]:loc[3]])
+			info.MovieTitle = cleanVODName(title[:loc[0]])
+		}
+	}
+	return info
+}
+
+func cleanVODName(s string) string {
+	return strings.TrimSpace(strings.Trim(strings.TrimSpace(s), "-:|([ "))
+}
+
+// atoiFirst converts the first non-empty string, 0 when none.
+func atoiFirst(values ...string) int {
+	for _, v := range values {
+		if v != "" {
+			n, _ := strconv.Atoi(v)
+			return n
+		}
+	}
+	return 0
+}
+
+// ContentType is the content-type attribute, live when missing.
+func (e *ExtInf) ContentType() string {
+	if t := e.GetAttr("content-type"); t != "" {
+		return t
+	}
+	return ContentLive
+}
+
+// Season is the season attribute, 0 when missing.
+func (e *ExtInf) Season() int {
+	n, _ := strconv.Atoi(e.GetAttr("season"))
+	return n
+}
+
+// Episode is the episode attribute, 0 when missing.
+func (e *ExtInf) Episode() int {
+	n, _ := strconv.Atoi(e.GetAttr("episode"))
+	return n
+}
+
+// classifyContents stores the derived content type of every entry, along with series-name, season and episode for
+// series (and 24/7 loops of a season), or movie-title and movie-year for movies.
+func (p *Playlist) classifyContents() {
+	for _, e := range p.Entries {
+		info := classifyContent(e)
+		e.Info.SetDerivedAttr("content-type", info.Type)
+		if info.SeriesName != "" {
+			e.Info.SetDerivedAttr("series-name", info.SeriesName)
+		}
+		if info.Season > 0 {
+			e.Info.SetDerivedAttr("season", strconv.Itoa(info.Season))
+		}
+		if info.Episode > 0 {
+			e.Info.SetDerivedAttr("episode", strconv.Itoa(info.Episode))
+		}
+		if info.MovieTitle != "" {
+			e.Info.SetDerivedAttr("movie-title", info.MovieTitle)
+		}
+		if info.MovieYear > 0 {
+			e.Info.SetDerivedAttr("movie-year", strconv.Itoa(info.MovieYear))
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestClassifyContent(t *testing.T) {
+	tests := []struct {
+		title, group, uri string
+		want              VODInfo
+	}{
+		{"24/7 The Sopranos S06 [VIP]", "24/7 Streams", "http://h/u/p/800000226", VODInfo{Type: Content247, SeriesName: "The Sopranos", Season: 6}},
+		{"24/7 Friends Season 6 [VIP]", "24/7 Streams", "http://h/u/p/800000317", VODInfo{Type: Content247, SeriesName: "Friends", Season: 6}},
+		{"24/7 THE SOPRANOS", "24/7 Streams", "http://h/u/p/40360", VODInfo{Type: Content247}},
+		{"UK: Sky Cinema Action", "UK Movies", "http://h/u/p/500001092", VODInfo{Type: ContentLive}},
+		{"Breaking Bad S02E05 HD", "US Series", "http://h/series/u/p/1.mkv", VODInfo{Type: ContentSeries, SeriesName: "Breaking Bad", Season: 2, Episode: 5}},
+		{"The Office 3x07", "US Series", "http://h/u/p/2", VODInfo{Type: ContentSeries, SeriesName: "The Office", Season: 3, Episode: 7}},
+		{"Heat (1995) [FHD]", "US Movies", "http://h/movie/u/p/3.mp4", VODInfo{Type: ContentMovie, MovieTitle: "Heat", MovieYear: 1995}},
+		{"Dune Part Two", "VOD", "http://h/movie/u/p/4.mkv", VODInfo{Type: ContentMovie, MovieTitle: "Dune Part Two"}},
+		{"Casino (1995)", "VOD Movies", "http://h/u/p/5", VODInfo{Type: ContentMovie, MovieTitle: "Casino", MovieYear: 1995}},
+		// A year alone does not make a live channel a movie
+		{"Euro 2024", "Sports", "http://h/u/p/6", VODInfo{Type: ContentLive}},
+	}
+	for _, tt := range tests {
+		e := newTestEntry(tt.title, map[string]string{"group-title": tt.group}, nil)
+		e.URI = tt.uri
+		if got := classifyContent(e); got != tt.want {
+			t.Errorf("%q: got %+v, want %+v", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestSortSeriesBySeason(t *testing.T) {
+	p := Playlist{}
+	for _, title := range []string{"24/7 Show S10", "24/7 Show S02", "24/7 Show Season 9", "24/7 Another S01"} {
+		p.Entries = append(p.Entries, newTestEntry(title, map[string]string{"group-title": "24/7 Streams"}, nil))
+	}
+	p.classifyContents()
+	out := p.generateOutput(SplitByType)
+	if len(out) != 1 {
+		t.Fatalf("got %d outputs, want 1", len(out))
+	}
+	loops := out["24/7"]
+	loops.sortEntries()
+	want := []string{"24/7 Another S01", "24/7 Show S02", "24/7 Show Season 9", "24/7 Show S10"}
+	for i, e := range loops.Entries {
+		if e.Info.Title != want[i] {
+			t.Errorf("#%d = %q, want %q", i, e.Info.Title, want[i])
+		}
+	}
+}
@@ -0,0 +1,622 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Filter expressions for --where, evaluated against each PlaylistEntry. Example:
+//
+//	group in ("NBA","US Sports") and title ~ "(?i)celtics" and start within 6h and not attr("tvg-logo") == ""
+//
+// Grammar, from lowest to highest precedence:
+//
+//	expr       := and ("or" and)*
+//	and        := unary ("and" unary)*
+//	unary      := "not" unary | comparison
+//	comparison := operand [("==" | "!=" | "~" | "!~" | "<" | "<=" | ">" | ">=") operand]
+//	            | operand "in" "(" operand ("," operand)* ")"
+//	            | operand "within" duration
+//	operand    := field | "attr" "(" string ")" | string | number | "true" | "false" | "(" expr ")"
+//
+// String comparisons (==, !=, in) are case-insensitive, like --group-title. ~ and !~ match Go regular expressions.
+// A bare operand is true when it is set: a non-empty string, a non-zero number or a parsed start time.
+
+type WhereExpr struct {
+	source string
+	root   whereNode
+}
+
+// WhereError is a syntax error with the 1-based column where it was found.
+type WhereError struct {
+	Column int
+	Msg    string
+}
+
+func (e *WhereError) Error() string {
+	return fmt.Sprintf("where: column %d: %s", e.Column, e.Msg)
+}
+
+type whereKind int
+
+const (
+	whereString whereKind = iota
+	whereNumber
+	whereTime
+	whereBool
+)
+
+func (k whereKind) String() string {
+	switch k {
+	case whereString:
+		return "string"
+	case whereNumber:
+		return "number"
+	case whereTime:
+		return "time"
+	}
+	return "bool"
+}
+
+type whereValue struct {
+	kind whereKind
+	str  string
+	num  float64
+	time *time.Time
+	bool bool
+}
+
+func (v whereValue) truthy() bool {
+	switch v.kind {
+	case whereString:
+		return v.str != ""
+	case whereNumber:
+		return v.num != 0
+	case whereTime:
+		return v.time != nil
+	}
+	return v.bool
+}
+
+type whereContext struct {
+	entry *PlaylistEntry
+	now   time.Time
+}
+
+// Fields available in expressions, besides attr("<name>")
+var whereFields = map[string]struct {
+	kind  whereKind
+	value func(e *PlaylistEntry) whereValue
+}{
+	"title":    {whereString, func(e *PlaylistEntry) whereValue { return whereValue{kind: whereString, str: e.Info.Title} }},
+	"group":    {whereString, func(e *PlaylistEntry) whereValue { return whereValue{kind: whereString, str: e.Info.GroupTitle()} }},
+	"uri":      {whereString, func(e *PlaylistEntry) whereValue { return whereValue{kind: whereString, str: e.URI} }},
+	"id":       {whereString, func(e *PlaylistEntry) whereValue { return whereValue{kind: whereString, str: e.Info.TvgID()} }},
+	"name":     {whereString, func(e *PlaylistEntry) whereValue { return whereValue{kind: whereString, str: e.Info.TvgName()} }},
+	"logo":     {whereString, func(e *PlaylistEntry) whereValue { return whereValue{kind: whereString, str: e.Info.TvgLogo()} }},
+	"match":    {whereString, func(e *PlaylistEntry) whereValue { return whereValue{kind: whereString, str: e.Info.NBAMatchId()} }},
+	"home":     {whereString, func(e *PlaylistEntry) whereValue { return whereValue{kind: whereString, str: e.Info.NBAHome()} }},
+	"away":     {whereString, func(e *PlaylistEntry) whereValue { return whereValue{kind: whereString, str: e.Info.NBAAway()} }},
+	"duration": {whereNumber, func(e *PlaylistEntry) whereValue { return whereValue{kind: whereNumber, num: float64(e.Info.Duration)} }},
+	"chno": {whereNumber, func(e *PlaylistEntry) whereValue {
+		chno, _ := e.Info.TvgChno()
+		return whereValue{kind: whereNumber, num: float64(chno)}
+	}},
+	"start":    {whereTime, func(e *PlaylistEntry) whereValue { return whereValue{kind: whereTime, time: e.Info.StartTimeLocal} }},
+	"favorite": {whereBool, func(e *PlaylistEntry) whereValue { return whereValue{kind: whereBool, bool: e.Info.Favorite} }},
+}
+
+// Match reports whether the entry satisfies the expression, relative times are measured from now.
+func (w *WhereExpr) Match(e *PlaylistEntry, now time.Time) bool {
+	return w.root.eval(&whereContext{entry: e, now: now}).truthy()
+}
+
+func (w *WhereExpr) String() string {
+	return w.source
+}
+
+func (p *Playlist) filterWhere(where *WhereExpr, now time.Time) {
+	out := p.Entries[:0]
+	for _, e := range p.Entries {
+		if where.Match(e, now) {
+			out = append(out, e)
+		}
+	}
+	p.Entries = out
+}
+
+// AST
+
+type whereNode interface {
+	eval(ctx *whereContext) whereValue
+	kind() whereKind
+}
+
+type whereLiteral struct{ value whereValue }
+
+func (n *whereLiteral) eval(*whereContext) whereValue { return n.value }
+func (n *whereLiteral) kind() whereKind               { return n.value.kind }
+
+type whereField struct {
+	fkind whereKind
+	value func(e *PlaylistEntry) whereValue
+}
+
+func (n *whereField) eval(ctx *whereContext) whereValue { return n.value(ctx.entry) }
+func (n *whereField) kind() whereKind                   { return n.fkind }
+
+type whereAttr struct{ key string }
+
+func (n *whereAttr) eval(ctx *whereContext) whereValue {
+	return whereValue{kind: whereString, str: ctx.entry.Info.GetAttr(n.key)}
+}
+func (n *whereAttr) kind() whereKind { return whereString }
+
+type whereLogical struct {
+	op          string // "and" or "or"
+	left, right whereNode
+}
+
+func (n *whereLogical) eval(ctx *whereContext) whereValue {
+	left := n.left.eval(ctx).truthy()
+	if n.op == "and" && !left || n.op == "or" && left {
+		return whereValue{kind: whereBool, bool: left}
+	}
+	return whereValue{kind: whereBool, bool: n.right.eval(ctx).truthy()}
+}
+func (n *whereLogical) kind() whereKind { return whereBool }
+
+type whereNot struct{ operand whereNode }
+
+func (n *whereNot) eval(ctx *whereContext) whereValue {
+	return whereValue{kind: whereBool, bool: !n.operand.eval(ctx).truthy()}
+}
+func (n *whereNot) kind() whereKind { return whereBool }
+
+type whereCompare struct {
+	op          string
+	left, right whereNode
+}
+
+func (n *whereCompare) eval(ctx *whereContext) whereValue {
+	left, right := n.left.eval(ctx), n.right.eval(ctx)
+	var cmp int
+	switch {
+	case left.kind == whereNumber && right.kind == whereNumber:
+		cmp = compareFloats(left.num, right.num)
+	case left.kind == whereTime || right.kind == whereTime:
+		// Times only compare with times, a missing start time never matches
+		if left.time == nil || right.time == nil {
+			return whereValue{kind: whereBool}
+		}
+		cmp = left.time.Compare(*right.time)
+	default:
+		cmp = strings.Compare(strings.ToLower(whereValueString(left)), strings.ToLower(whereValueString(right)))
+	}
+	var result bool
+	switch n.op {
+	case "==":
+		result = cmp == 0
+	case "!=":
+		result = cmp != 0
+	case "<":
+		result = cmp < 0
+	case "<=":
+		result = cmp <= 0
+	case ">":
+		result = cmp > 0
+	case ">=":
+		result = cmp >= 0
+	}
+	return whereValue{kind: whereBool, bool: result}
+}
+func (n *whereCompare) kind() whereKind { return whereBool }
+
+type whereRegex struct {
+	negate  bool
+	operand whereNode
+	re      *regexp.Regexp
+}
+
+func (n *whereRegex) eval(ctx *whereContext) whereValue {
+	matched := n.re.MatchString(whereValueString(n.operand.eval(ctx)))
+	return whereValue{kind: whereBool, bool: matched != n.negate}
+}
+func (n *whereRegex) kind() whereKind { return whereBool }
+
+type whereIn struct {
+	operand whereNode
+	values  []whereNode
+}
+
+func (n *whereIn) eval(ctx *whereContext) whereValue {
+	value := whereValueString(n.operand.eval(ctx))
+	for _, candidate := range n.values {
+		if strings.EqualFold(value, whereValueString(candidate.eval(ctx))) {
+			return whereValue{kind: whereBool, bool: true}
+		}
+	}
+	return whereValue{kind: whereBool}
+}
+func (n *whereIn) kind() whereKind { return whereBool }
+
+type whereWithin struct {
+	operand whereNode
+	window  time.Duration
+}
+
+func (n *whereWithin) eval(ctx *whereContext) whereValue {
+	t := n.operand.eval(ctx).time
+	if t == nil {
+		return whereValue{kind: whereBool}
+	}
+	diff := t.Sub(ctx.now)
+	return whereValue{kind: whereBool, bool: diff >= -n.window && diff <= n.window}
+}
+func (n *whereWithin) kind() whereKind { return whereBool }
+
+func whereValueString(v whereValue) string {
+	switch v.kind {
+	case whereNumber:
+		return strconv.FormatFloat(v.num, 'f', -1, 64)
+	case whereTime:
+		if v.time == nil {
+			return ""
+		}
+		return v.time.Format(time.RFC3339)
+	case whereBool:
+		return strconv.FormatBool(v.bool)
+	}
+	return v.str
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Lexer
+
+type whereTokenKind int
+
+const (
+	tokEOF whereTokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokDuration
+	tokOperator
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type whereToken struct {
+	kind   whereTokenKind
+	text   string // identifiers are lowercased, strings unquoted
+	column int
+	dur    time.Duration
+}
+
+func (t whereToken) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func lexWhere(src string) ([]whereToken, error) {
+	var tokens []whereToken
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		column := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, whereToken{kind: tokLParen, text: "(", column: column})
+			i++
+		case r == ')':
+			tokens = append(tokens, whereToken{kind: tokRParen, text: ")", column: column})
+			i++
+		case r == ',':
+			tokens = append(tokens, whereToken{kind: tokComma, text: ",", column: column})
+			i++
+		case r == '"' || r == '\'':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != r; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				b.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, &WhereError{column, "unterminated string"}
+			}
+			tokens = append(tokens, whereToken{kind: tokString, text: b.String(), column: column})
+			i = j + 1
+		case strings.ContainsRune("=!<>~", r):
+			op := string(r)
+			if i+1 < len(runes) && (runes[i+1] == '=' || r == '!' && runes[i+1] == '~') {
+				op += string(runes[i+1])
+			}
+			switch op {
+			case "==", "!=", "~", "!~", "<", "<=", ">", ">=":
+			default:
+				return nil, &WhereError{column, fmt.Sprintf("unknown operator %q", op)}
+			}
+			tokens = append(tokens, whereToken{kind: tokOperator, text: op, column: column})
+			i += len([]rune(op))
+		case unicode.IsDigit(r) || r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.' || unicode.IsLetter(runes[j])) {
+				j++
+			}
+			text := string(runes[i:j])
+			if n, err := strconv.ParseFloat(text, 64); err == nil {
+				tokens = append(tokens, whereToken{kind: tokNumber, text: strconv.FormatFloat(n, 'f', -1, 64), column: column})
+			} else if d, err := parseWhereDuration(text); err == nil {
+				tokens = append(tokens, whereToken{kind: tokDuration, text: text, dur: d, column: column})
+			} else {
+				return nil, &WhereError{column, fmt.Sprintf("invalid number or duration %q", text)}
+			}
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i + 1
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_' || runes[j] == '-') {
+				j++
+			}
+			tokens = append(tokens, whereToken{kind: tokIdent, text: strings.ToLower(string(runes[i:j])), column: column})
+			i = j
+		default:
+			return nil, &WhereError{column, fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+	return append(tokens, whereToken{kind: tokEOF, column: len(runes) + 1}), nil
+}
+
+// parseWhereDuration accepts Go durations plus days, e.g. "6h", "90m", "1d", "1d12h".
+func parseWhereDuration(text string) (time.Duration, error) {
+	days := time.Duration(0)
+	if i := strings.IndexByte(text, 'd'); i > 0 {
+		n, err := strconv.Atoi(text[:i])
+		if err != nil {
+			return 0, err
+		}
+		days = time.Duration(n) * 24 * time.Hour
+		text = text[i+1:]
+		if text == "" {
+			return days, nil
+		}
+	}
+	d, err := time.ParseDuration(text)
+	if err != nil {
+		return 0, err
+	}
+	return days + d, nil
+}
+
+// Parser
+
+type whereParser struct {
+	tokens []whereToken
+	pos    int
+}
+
+func parseWhere(src string) (*WhereExpr, error) {
+	tokens, err := lexWhere(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &whereParser{tokens: tokens}
+	if p.peek().kind == tokEOF {
+		return nil, &WhereError{1, "empty expression"}
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "unexpected %s, expected \"and\", \"or\" or end of expression", tok.describe())
+	}
+	return &WhereExpr{source: src, root: root}, nil
+}
+
+func (p *whereParser) peek() whereToken {
+	return p.tokens[p.pos]
+}
+
+func (p *whereParser) next() whereToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *whereParser) isKeyword(word string) bool {
+	tok := p.peek()
+	return tok.kind == tokIdent && tok.text == word
+}
+
+func (p *whereParser) errorf(tok whereToken, format string, args ...any) error {
+	return &WhereError{tok.column, fmt.Sprintf(format, args...)}
+}
+
+func (p *whereParser) expect(kind whereTokenKind, what string) (whereToken, error) {
+	tok := p.next()
+	if tok.kind != kind {
+		return tok, p.errorf(tok, "expected %s, got %s", what, tok.describe())
+	}
+	return tok, nil
+}
+
+func (p *whereParser) parseOr() (whereNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &whereLogical{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *whereParser) parseAnd() (whereNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &whereLogical{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *whereParser) parseUnary() (whereNode, error) {
+	if p.isKeyword("not") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &whereNot{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *whereParser) parseComparison() (whereNode, error) {
+	leftTok := p.peek()
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	tok := p.peek()
+	switch {
+	case tok.kind == tokOperator:
+		p.next()
+		rightTok := p.peek()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if tok.text == "~" || tok.text == "!~" {
+			literal, ok := right.(*whereLiteral)
+			if !ok || literal.value.kind != whereString {
+				return nil, p.errorf(rightTok, "%s expects a regular expression string", tok.text)
+			}
+			re, err := regexp.Compile(literal.value.str)
+			if err != nil {
+				return nil, p.errorf(rightTok, "invalid regular expression: %v", err)
+			}
+			return &whereRegex{negate: tok.text == "!~", operand: left, re: re}, nil
+		}
+		if (left.kind() == whereTime) != (right.kind() == whereTime) {
+			return nil, p.errorf(tok, "cannot compare %s with %s, use \"within\" for start times", left.kind(), right.kind())
+		}
+		return &whereCompare{op: tok.text, left: left, right: right}, nil
+	case p.isKeyword("in"):
+		p.next()
+		if _, err := p.expect(tokLParen, "\"(\" after in"); err != nil {
+			return nil, err
+		}
+		var values []whereNode
+		for {
+			value, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+			sep := p.next()
+			if sep.kind == tokRParen {
+				break
+			}
+			if sep.kind != tokComma {
+				return nil, p.errorf(sep, "expected \",\" or \")\" in list, got %s", sep.describe())
+			}
+		}
+		return &whereIn{operand: left, values: values}, nil
+	case p.isKeyword("within"):
+		p.next()
+		if left.kind() != whereTime {
+			return nil, p.errorf(leftTok, "within applies to start, got %s", left.kind())
+		}
+		durTok, err := p.expect(tokDuration, "a duration like 6h or 1d")
+		if err != nil {
+			return nil, err
+		}
+		return &whereWithin{operand: left, window: durTok.dur}, nil
+	}
+	return left, nil
+}
+
+func (p *whereParser) parseOperand() (whereNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokString:
+		return &whereLiteral{whereValue{kind: whereString, str: tok.text}}, nil
+	case tokNumber:
+		n, _ := strconv.ParseFloat(tok.text, 64)
+		return &whereLiteral{whereValue{kind: whereNumber, num: n}}, nil
+	case tokDuration:
+		return nil, p.errorf(tok, "duration %s is only valid after within", tok.describe())
+	case tokLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, "\")\""); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case tokIdent:
+		switch tok.text {
+		case "true", "false":
+			return &whereLiteral{whereValue{kind: whereBool, bool: tok.text == "true"}}, nil
+		case "attr":
+			if _, err := p.expect(tokLParen, "\"(\" after attr"); err != nil {
+				return nil, err
+			}
+			key, err := p.expect(tokString, "attribute name string")
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(tokRParen, "\")\""); err != nil {
+				return nil, err
+			}
+			return &whereAttr{key: key.text}, nil
+		case "and", "or", "not", "in", "within":
+			return nil, p.errorf(tok, "unexpected keyword %s", tok.describe())
+		}
+		field, ok := whereFields[tok.text]
+		if !ok {
+			return nil, p.errorf(tok, "unknown field %s", tok.describe())
+		}
+		return &whereField{fkind: field.kind, value: field.value}, nil
+	}
+	return nil, p.errorf(tok, "unexpected %s", tok.describe())
+}
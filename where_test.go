@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWhereOperators(t *testing.T) {
+	now := time.Date(2025, 12, 6, 20, 0, 0, 0, time.UTC)
+	soon := now.Add(2 * time.Hour)
+	entry := newTestEntry("NBA 05: Celtics (BOS) vs Lakers (LAL)", map[string]string{
+		"group-title": "NBA",
+		"tvg-logo":    "",
+		"tvg-chno":    "12",
+		"nba-home":    "BOS",
+	}, &soon)
+	noStart := newTestEntry("ESPN HD", map[string]string{"group-title": "US Sports"}, nil)
+
+	tests := []struct {
+		expr  string
+		entry *PlaylistEntry
+		want  bool
+	}{
+		{`group == "nba"`, entry, true},
+		{`group == "NFL"`, entry, false},
+		{`group != "NFL"`, entry, true},
+		{`title ~ "(?i)celtics"`, entry, true},
+		{`title ~ "celtics"`, entry, false},
+		{`title !~ "Knicks"`, entry, true},
+		{`chno < 13`, entry, true},
+		{`chno <= 12`, entry, true},
+		{`chno > 12`, entry, false},
+		{`chno >= 12`, entry, true},
+		{`duration == 0`, entry, true},
+		{`duration > -1`, entry, true},
+		{`group in ("NFL", "nba")`, entry, true},
+		{`group in ("NFL")`, entry, false},
+		{`start within 6h`, entry, true},
+		{`start within 1h`, entry, false},
+		{`start within 1d`, noStart, false},
+		{`start`, entry, true},
+		{`start`, noStart, false},
+		{`group == "NBA" and home == "BOS"`, entry, true},
+		{`group == "NBA" and home == "LAL"`, entry, false},
+		{`group == "NFL" or home == "BOS"`, entry, true},
+		{`not group == "NBA"`, entry, false},
+		{`not attr("tvg-logo") == ""`, entry, false},
+		{`attr("TVG-CHNO") == "12"`, entry, true},
+		{`not (group == "NFL" or group == "NBA") or favorite`, entry, false},
+		{`favorite == false`, entry, true},
+		{`group in ("NBA","US Sports") and title ~ "(?i)celtics" and start within 6h and not attr("tvg-logo") == ""`, entry, false},
+	}
+	for _, tt := range tests {
+		where, err := parseWhere(tt.expr)
+		if err != nil {
+			t.Errorf("parseWhere(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := where.Match(tt.entry, now); got != tt.want {
+			t.Errorf("%q on %q = %v, want %v", tt.expr, tt.entry.Info.Title, got, tt.want)
+		}
+	}
+}
+
+func TestWhereSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{``, "column 1: empty expression"},
+		{`group == "NBA" and`, `column 19: unexpected end of expression`},
+		{`group = "NBA"`, `column 7: unknown operator "="`},
+		{`group == "NBA`, `column 10: unterminated string`},
+		{`team == "BOS"`, `column 1: unknown field "team"`},
+		{`title ~ "(celtics"`, `column 9: invalid regular expression`},
+		{`title ~ group`, `column 9: ~ expects a regular expression string`},
+		{`group in ("NBA" "NFL")`, `column 17: expected "," or ")" in list, got "NFL"`},
+		{`start within soon`, `column 14: expected a duration like 6h or 1d, got "soon"`},
+		{`title within 6h`, `column 1: within applies to start, got string`},
+		{`start > "2025"`, `column 7: cannot compare time with string`},
+		{`(group == "NBA"`, `column 16: expected ")", got end of expression`},
+		{`group == "NBA" title`, `column 16: unexpected "title"`},
+		{`attr(tvg-logo) == ""`, `column 6: expected attribute name string`},
+	}
+	for _, tt := range tests {
+		_, err := parseWhere(tt.expr)
+		if err == nil {
+			t.Errorf("parseWhere(%q) succeeded, want error %q", tt.expr, tt.want)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseWhere(%q) error = %q, want it to contain %q", tt.expr, err, tt.want)
+		}
+	}
+}
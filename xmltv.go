@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Programme is one show of an XMLTV guide.
+type Programme struct {
+	Channel string
+	Title   string
+	Start   time.Time
+	Stop    time.Time
+}
+
+// Guide holds the programmes of an XMLTV file by lower-cased channel id, ordered by start.
+type Guide struct {
+	Programmes map[string][]Programme
+}
+
+var xmltvTimeFormats = []string{"20060102150405 -0700", "20060102150405 MST", "20060102150405", "200601021504 -0700", "200601021504"}
+
+func parseXMLTVTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, format := range xmltvTimeFormats {
+		if t, err := time.Parse(format, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid XMLTV time %q", s)
+}
+
+// openGuide opens a local XMLTV file or downloads it, gunzipping it when needed.
+func openGuide(source string) (io.ReadCloser, error) {
+	var body io.ReadCloser
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		client := &http.Client{Timeout: 2 * time.Minute}
+		resp, err := client.Get(source)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%s: %s", source, resp.Status)
+		}
+		body = resp.Body
+	} else {
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		body = f
+	}
+	r := bufio.NewReader(body)
+	// Guides are often served as .xml.gz
+	if magic, err := r.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			body.Close()
+			return nil, err
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{gz, body}, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{r, body}, nil
+}
+
+// loadGuide reads the programmes of the given channels (lower-cased ids, all when nil) that end after since.
+// Guides can be large, so the file is streamed and other programmes are skipped.
+func loadGuide(source string, channels map[string]bool, since time.Time) (*Guide, error) {
+	in, err := openGuide(source)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	guide := &Guide{Programmes: make(map[string][]Programme)}
+	dec := xml.NewDecoder(in)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok || se.Name.Local != "programme" {
+			continue
+		}
+		var p struct {
+			Start   string   `xml:"start,attr"`
+			Stop    string   `xml:"stop,attr"`
+			Channel string   `xml:"channel,attr"`
+			Titles  []string `xml:"title"`
+		}
+		if err := dec.DecodeElement(&p, &se); err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+		id := strings.ToLower(strings.TrimSpace(p.Channel))
+		if channels != nil && !channels[id] {
+			continue
+		}
+		start, err := parseXMLTVTime(p.Start)
+		if err != nil {
+			return nil, fmt.Errorf("%s: channel %s: %w", source, p.Channel, err)
+		}
+		// A missing stop ends the programme when the next one starts
+		var stop time.Time
+		if p.Stop != "" {
+			if stop, err = parseXMLTVTime(p.Stop); err != nil {
+				return nil, fmt.Errorf("%s: channel %s: %w", source, p.Channel, err)
+			}
+			if !stop.After(since) {
+				continue
+			}
+		}
+		title := ""
+		if len(p.Titles) > 0 {
+			title = strings.TrimSpace(p.Titles[0])
+		}
+		guide.Programmes[id] = append(guide.Programmes[id], Programme{Channel: p.Channel, Title: title, Start: start, Stop: stop})
+	}
+	for id, programmes := range guide.Programmes {
+		sort.SliceStable(programmes, func(i, j int) bool { return programmes[i].Start.Before(programmes[j].Start) })
+		for i := range programmes {
+			if programmes[i].Stop.IsZero() && i+1 < len(programmes) {
+				programmes[i].Stop = programmes[i+1].Start
+			}
+		}
+		guide.Programmes[id] = programmes
+	}
+	return guide, nil
+}
+
+// NowNext returns the programme on air at now and the one after it, nil when unknown.
+func (g *Guide) NowNext(channel string, now time.Time) (current, next *Programme) {
+	programmes := g.Programmes[strings.ToLower(strings.TrimSpace(channel))]
+	for i := range programmes {
+		p := &programmes[i]
+		if p.Start.After(now) {
+			return current, p
+		}
+		if p.Stop.IsZero() || p.Stop.After(now) {
+			current = p
+		}
+	}
+	return current, nil
+}
+
+// annotateGuide sets epg-now and epg-next (titles), epg-now-start and epg-next-start (RFC 3339) on the entries
+// with a tvg-id in the guide, and epg-start: the start of the programme matching match (current first), or of
+// the current programme (the next one when none) without match. With match, the entries without a matching
+// current or next programme are dropped. Like the other derived attributes, they are written with --annotate only.
+func (p *Playlist) annotateGuide(guide *Guide, now time.Time, match *regexp.Regexp) {
+	out := p.Entries[:0]
+	for _, e := range p.Entries {
+		current, next := guide.NowNext(e.Info.TvgID(), now)
+		for _, prog := range []struct {
+			key string
+			p   *Programme
+		}{{"epg-now", current}, {"epg-next", next}} {
+			if prog.p == nil {
+				continue
+			}
+			e.Info.SetDerivedAttr(prog.key, prog.p.Title)
+			e.Info.SetDerivedAttr(prog.key+"-start", prog.p.Start.In(time.Local).Format(time.RFC3339))
+		}
+		var selected *Programme
+		for _, prog := range []*Programme{current, next} {
+			if prog != nil && selected == nil && (match == nil || match.MatchString(prog.Title)) {
+				selected = prog
+			}
+		}
+		if selected == nil && match != nil {
+			continue
+		}
+		if selected != nil {
+			e.Info.SetDerivedAttr("epg-start", selected.Start.In(time.Local).Format(time.RFC3339))
+		}
+		out = append(out, e)
+	}
+	p.Entries = out
+}
+
+// guideChannels lists the lower-cased tvg-ids of the playlist, the only channels worth reading from a guide.
+func (p *Playlist) guideChannels() map[string]bool {
+	channels := make(map[string]bool)
+	for _, e := range p.Entries {
+		if id := strings.ToLower(strings.TrimSpace(e.Info.TvgID())); id != "" {
+			channels[id] = true
+		}
+	}
+	return channels
+}
+
+// EPGStart is the start of the programme annotated by annotateGuide.
+func (e *ExtInf) EPGStart() (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339, e.GetAttr("epg-start"))
+	return t, err == nil
+}
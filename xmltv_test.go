@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+)
+
+const testGuide = `<?xml version="1.0" encoding="UTF-8"?>
+<tv>
+  <channel id="espn.us"><display-name>ESPN</display-name></channel>
+  <programme start="20251206180000 +0000" stop="20251206200000 +0000" channel="ESPN.us"><title>SportsCenter</title></programme>
+  <programme start="20251206200000 +0000" stop="20251206223000 +0000" channel="espn.us"><title lang="en">NBA: Heat vs Kings</title></programme>
+  <programme start="20251206223000 +0000" channel="espn.us"><title>Post Game</title></programme>
+  <programme start="20251206210000 +0000" stop="20251206230000 +0000" channel="tnt.us"><title>NBA: Lakers vs Celtics</title></programme>
+  <programme start="20251206190000 +0000" stop="20251206210000 +0000" channel="cnn.us"><title>News</title></programme>
+  <programme start="20251206150000 +0000" stop="20251206160000 +0000" channel="hbo.us"><title>Over</title></programme>
+</tv>
+`
+
+func TestLoadGuide(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(testGuide))
+	w.Close()
+	path := filepath.Join(t.TempDir(), "guide.xml.gz")
+	if err := os.WriteFile(path, gz.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 12, 6, 19, 0, 0, 0, time.UTC)
+	guide, err := loadGuide(path, map[string]bool{"espn.us": true, "tnt.us": true, "hbo.us": true}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(guide.Programmes["espn.us"]) != 3 || len(guide.Programmes["tnt.us"]) != 1 {
+		t.Errorf("programmes = %v", guide.Programmes)
+	}
+	if _, ok := guide.Programmes["cnn.us"]; ok {
+		t.Error("channel outside the playlist loaded")
+	}
+	if _, ok := guide.Programmes["hbo.us"]; ok {
+		t.Error("ended programme loaded")
+	}
+	current, next := guide.NowNext("ESPN.us", now.Add(3*time.Hour))
+	if current == nil || current.Title != "NBA: Heat vs Kings" || next == nil || next.Title != "Post Game" {
+		t.Errorf("NowNext = %v, %v", current, next)
+	}
+	if current, next = guide.NowNext("espn.us", now.Add(5*time.Hour)); current == nil || current.Title != "Post Game" || next != nil {
+		t.Errorf("NowNext after the last start = %v, %v", current, next)
+	}
+}
+
+func TestAnnotateGuide(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testGuide))
+	}))
+	defer server.Close()
+	guide, err := loadGuide(server.URL+"/guide.xml", nil, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2025, 12, 6, 19, 0, 0, 0, time.UTC)
+	p := Playlist{Entries: []*PlaylistEntry{
+		newTestEntry("ESPN", map[string]string{"tvg-id": "espn.us"}, nil),
+		newTestEntry("TNT", map[string]string{"tvg-id": "tnt.us"}, nil),
+		newTestEntry("CNN", map[string]string{"tvg-id": "cnn.us"}, nil),
+		newTestEntry("Local", nil, nil),
+	}}
+	p.annotateGuide(guide, now, regexp.MustCompile("(?i)nba"))
+	if len(p.Entries) != 2 {
+		t.Fatalf("kept %d entries, want ESPN and TNT", len(p.Entries))
+	}
+	espn := p.Entries[0].Info
+	if espn.GetAttr("epg-now") != "SportsCenter" || espn.GetAttr("epg-next") != "NBA: Heat vs Kings" {
+		t.Errorf("ESPN now/next = %q/%q", espn.GetAttr("epg-now"), espn.GetAttr("epg-next"))
+	}
+	if start, _ := time.Parse(time.RFC3339, espn.GetAttr("epg-now-start")); !start.Equal(now.Add(-time.Hour)) {
+		t.Errorf("epg-now-start = %q", espn.GetAttr("epg-now-start"))
+	}
+	if slices.Contains(espn.OutputAttrKeys(), "epg-now") {
+		t.Errorf("epg-now written without --annotate: %v", espn.OutputAttrKeys())
+	}
+	// The matching programme, not the current one, is the start to sort by
+	if start, ok := espn.EPGStart(); !ok || !start.Equal(now.Add(time.Hour)) {
+		t.Errorf("ESPN epg-start = %q", espn.GetAttr("epg-start"))
+	}
+
+	spec, err := parseSortSpec("programme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := PlaylistOutput{Entries: []*PlaylistEntry{p.Entries[1], p.Entries[0]}, SortSpec: spec}
+	out.sortEntries()
+	if out.Entries[0].Info.Title != "ESPN" || out.Entries[1].Info.Title != "TNT" {
+		t.Errorf("sorted by programme: %s, %s", out.Entries[0].Info.Title, out.Entries[1].Info.Title)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+)
+
+// XSPF playlist with the VLC extension, which is where VLC keeps the groups (as nodes)
+type xspfPlaylist struct {
+	XMLName   xml.Name      `xml:"playlist"`
+	Version   string        `xml:"version,attr"`
+	Xmlns     string        `xml:"xmlns,attr"`
+	XmlnsVLC  string        `xml:"xmlns:vlc,attr"`
+	Title     string        `xml:"title,omitempty"`
+	Tracks    []xspfTrack   `xml:"trackList>track"`
+	Extension *xspfGroupExt `xml:"extension,omitempty"`
+}
+
+type xspfTrack struct {
+	Location  string       `xml:"location"`
+	Title     string       `xml:"title"`
+	Image     string       `xml:"image,omitempty"`
+	Duration  int          `xml:"duration,omitempty"` // milliseconds
+	Extension xspfTrackExt `xml:"extension"`
+}
+
+type xspfTrackExt struct {
+	Application string `xml:"application,attr"`
+	ID          int    `xml:"vlc:id"`
+}
+
+type xspfGroupExt struct {
+	Application string     `xml:"application,attr"`
+	Nodes       []xspfNode `xml:"vlc:node"`
+}
+
+type xspfNode struct {
+	Title string     `xml:"title,attr"`
+	Items []xspfItem `xml:"vlc:item"`
+}
+
+type xspfItem struct {
+	TID int `xml:"tid,attr"`
+}
+
+const FormatXSPF = "xspf"
+
+const xspfVLCApplication = "http://www.videolan.org/vlc/playlist/0"
+
+// writeXSPF writes the entries as an XSPF playlist: title, location, logo as image, duration, and groups as
+// VLC nodes.
+func writeXSPF(outPath, title string, entries []*PlaylistEntry) error {
+	doc := xspfPlaylist{
+		Version:  "1",
+		Xmlns:    "http://xspf.org/ns/0/",
+		XmlnsVLC: "http://www.videolan.org/vlc/playlist/ns/0/",
+		Title:    title,
+	}
+	groups := make(map[string]int)
+	var nodes []xspfNode
+	for i, e := range entries {
+		track := xspfTrack{
+			Location:  e.URI,
+			Title:     e.Info.Title,
+			Image:     e.Info.TvgLogo(),
+			Extension: xspfTrackExt{Application: xspfVLCApplication, ID: i},
+		}
+		if e.Info.Duration > 0 {
+			track.Duration = e.Info.Duration * 1000
+		}
+		doc.Tracks = append(doc.Tracks, track)
+		group := e.Info.GroupTitle()
+		if group == "" {
+			continue
+		}
+		n, ok := groups[group]
+		if !ok {
+			n = len(nodes)
+			groups[group] = n
+			nodes = append(nodes, xspfNode{Title: group})
+		}
+		nodes[n].Items = append(nodes[n].Items, xspfItem{TID: i})
+	}
+	if len(nodes) > 0 {
+		doc.Extension = &xspfGroupExt{Application: xspfVLCApplication, Nodes: nodes}
+	}
+	return writeXMLFile(outPath, doc)
+}
+
+func writeXMLFile(outPath string, doc any) error {
+	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	w.WriteString(xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	w.WriteString("\n")
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
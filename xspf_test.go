@@ -0,0 +1,14 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteXSPF_Golden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.xspf")
+	if err := writeXSPF(path, "Golden", goldenEntries()); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, path, "golden.xspf")
+}